@@ -0,0 +1,232 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// binding is a value registered to the Container which is resolved on demand
+type binding interface {
+	// Type returns the type of resolved value
+	Type() reflect.Type
+	resolve(c *Container) (reflect.Value, error)
+}
+
+var bindingType = reflect.TypeOf((*binding)(nil)).Elem()
+
+// Lazy is a constructor registered to the Container which is invoked
+// on the first resolution of its value. Created value is reused afterwards.
+//
+// Constructor parameters are resolved from the container which resolves the value.
+// Constructor can return the same results as constructors used by Provide.
+// Lazy constructors depending on each other fail with dependency cycle error.
+type Lazy struct {
+	fn          reflect.Value
+	constructor *Constructor
+	// create creates the value instead of fn, see NewLazyFunc
+	create func() (interface{}, error)
+
+	mu       sync.Mutex
+	created  bool
+	value    reflect.Value
+	cleanup  func()
+	onCreate []func(interface{})
+}
+
+// NewLazy creates Lazy constructor. Created value has to be added
+// to the Container in order to be resolved.
+func NewLazy(constructor interface{}) (*Lazy, error) {
+	c, err := Inspect(constructor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lazy{
+		fn:          reflect.ValueOf(constructor),
+		constructor: c,
+	}, nil
+}
+
+// NewLazyFunc creates Lazy which creates the value described by constructor
+// using given function, eg. to defer invocation of constructor which is not
+// invoked by the Container. Created value has to be added to the Container in order to be resolved.
+func NewLazyFunc(constructor *Constructor, create func() (interface{}, error)) *Lazy {
+	return &Lazy{
+		constructor: constructor,
+		create:      create,
+	}
+}
+
+// Type returns the type of the value created by Lazy constructor
+func (l *Lazy) Type() reflect.Type {
+	return l.constructor.Result
+}
+
+// Cleanup invokes cleanup function returned by constructor, if value was created
+func (l *Lazy) Cleanup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cleanup != nil {
+		l.cleanup()
+		l.cleanup = nil
+	}
+}
+
+// OnCreate registers function invoked with the value created by Lazy constructor.
+// Function is invoked immediately when the value is already created.
+func (l *Lazy) OnCreate(fn func(value interface{})) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.created {
+		fn(l.value.Interface())
+		return
+	}
+	l.onCreate = append(l.onCreate, fn)
+}
+
+// Value returns the value created by Lazy constructor, the value is created
+// when it is not created yet with constructor parameters resolved from given container
+func (l *Lazy) Value(c *Container) (interface{}, error) {
+	v, err := l.resolve(c)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func (l *Lazy) resolve(c *Container) (reflect.Value, error) {
+	// constructor depending on its own value would wait for itself
+	if c.isResolving(l) {
+		return reflect.Value{}, fmt.Errorf("lazy dependency cycle detected for constructor %s (%s)", l.constructor.Name, l.constructor.Location)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.created {
+		return l.value, nil
+	}
+
+	// failed constructor is invoked again on the next resolution
+	v, cleanup, err := l.call(c)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	l.created = true
+	l.value = v
+	l.cleanup = cleanup
+	for _, fn := range l.onCreate {
+		fn(v.Interface())
+	}
+	l.onCreate = nil
+	return v, nil
+}
+
+// call invokes constructor or create function of Lazy
+func (l *Lazy) call(c *Container) (reflect.Value, func(), error) {
+	if l.create == nil {
+		return c.resolvingWith(l).call(l.fn)
+	}
+
+	obj, err := l.create()
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() {
+		v = reflect.Zero(l.constructor.Result)
+	}
+	return v, nil, nil
+}
+
+// Factory is a constructor registered to the Container which is invoked
+// on every resolution of its value, so each consumer receives new instance.
+//
+// Constructor parameters are resolved from the container which resolves the value.
+// Constructor has to return the value and optionally an error, eg. func(...) T or func(...) (T, error).
+type Factory struct {
+	fn          reflect.Value
+	constructor *Constructor
+}
+
+// NewFactory creates Factory constructor. Created value has to be added
+// to the Container in order to be resolved.
+func NewFactory(constructor interface{}) (*Factory, error) {
+	c, err := Inspect(constructor)
+	if err != nil {
+		return nil, err
+	}
+
+	if reflect.TypeOf(constructor).NumOut() == 3 {
+		return nil, fmt.Errorf("invalid factory constructor %s (%s). Error: factory constructor can not return cleanup function", c.Name, c.Location)
+	}
+
+	return &Factory{
+		fn:          reflect.ValueOf(constructor),
+		constructor: c,
+	}, nil
+}
+
+// Type returns the type of the value created by Factory constructor
+func (f *Factory) Type() reflect.Type {
+	return f.constructor.Result
+}
+
+func (f *Factory) resolve(c *Container) (reflect.Value, error) {
+	v, _, err := c.call(f.fn)
+	return v, err
+}
+
+// Alias binds the type to the value of another type registered to the Container,
+// eg. an interface to one of its implementations.
+//
+// Alias of an interface takes precedence over other implementations of the interface.
+type Alias struct {
+	typ    reflect.Type
+	target reflect.Type
+}
+
+// NewAlias creates Alias of given type resolved to the value of target type.
+// Created value has to be added to the Container in order to be resolved.
+func NewAlias(typ reflect.Type, target reflect.Type) (*Alias, error) {
+	if typ == nil || target == nil {
+		return nil, errors.New("alias types can not be nil")
+	}
+	if typ == target {
+		return nil, fmt.Errorf("type `%s` can not be an alias of itself", typ)
+	}
+	if !equalTypes(target, typ) {
+		return nil, fmt.Errorf("type `%s` is not assignable to `%s`", target, typ)
+	}
+
+	return &Alias{
+		typ:    typ,
+		target: target,
+	}, nil
+}
+
+// Type returns the aliased type
+func (a *Alias) Type() reflect.Type {
+	return a.typ
+}
+
+// Target returns the type of the value the alias resolves to
+func (a *Alias) Target() reflect.Type {
+	return a.target
+}
+
+func (a *Alias) resolve(c *Container) (reflect.Value, error) {
+	v, ok, err := c.lookup(a.target)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("aliased type `%s` is not registered", a.target)
+	}
+	return v, nil
+}
@@ -0,0 +1,154 @@
+package di
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContainerLazy(t *testing.T) {
+	c := NewContainer()
+
+	calls, cleaned := 0, false
+	lazy, err := NewLazy(func(cnt *counter) (*english, func(), error) {
+		calls++
+		return &english{name: "lazy"}, func() { cleaned = true }, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(lazy)
+	if calls != 0 {
+		t.Fatal("lazy constructor should not be invoked on registration")
+	}
+	var created []interface{}
+	lazy.OnCreate(func(v interface{}) { created = append(created, v) })
+
+	// dependency is registered after lazy constructor
+	c.Add(&counter{n: 1})
+
+	for i := 0; i < 2; i++ {
+		v, err := c.Provide(func(g greeter) string { return g.Greet() })
+		if err != nil || v != "hello lazy" {
+			t.Fatalf("wrong lazy value: %v, %v", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("lazy constructor should be invoked once, got %d calls", calls)
+	}
+	lazy.OnCreate(func(v interface{}) { created = append(created, v) })
+	if len(created) != 2 || created[0] != created[1] {
+		t.Errorf("created value should be passed to OnCreate functions once: %v", created)
+	}
+
+	lazy.Cleanup()
+	if !cleaned {
+		t.Error("expected cleanup of lazy value")
+	}
+}
+
+func TestContainerLazyCycle(t *testing.T) {
+	c := NewContainer()
+	first, _ := NewLazy(func(g *german) *english { return &english{} })
+	second, _ := NewLazy(func(e *english) *german { return &german{} })
+	c.Add(first)
+	c.Add(second)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Provide(func(e *english) int { return 1 })
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "lazy dependency cycle detected") {
+			t.Errorf("expected lazy dependency cycle error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lazy values depending on each other should not wait for themselves")
+	}
+}
+
+func TestContainerLazyError(t *testing.T) {
+	errConnect := errors.New("connection refused")
+
+	c := NewContainer()
+	lazy, _ := NewLazy(func() (*english, error) { return nil, errConnect })
+	c.Add(lazy)
+
+	_, err := c.Provide(func(e *english) int { return 1 })
+	var depErr *DependencyError
+	if !errors.As(err, &depErr) || !errors.Is(err, errConnect) {
+		t.Fatalf("expected dependency error wrapping constructor error, got: %v", err)
+	}
+	if !c.CanResolve(Dependency{Type: reflect.TypeOf(&english{})}) {
+		t.Error("lazy value should be resolvable without invoking constructor")
+	}
+}
+
+func TestContainerFactory(t *testing.T) {
+	c := NewContainer()
+	n := 0
+	factory, err := NewFactory(func() *counter {
+		n++
+		return &counter{n: n}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(factory)
+
+	v, err := c.Provide(func(a, b *counter) int { return a.n + b.n })
+	if err != nil || v != 3 {
+		t.Errorf("expected new instance per injection, got %v, %v", v, err)
+	}
+
+	if _, err := NewFactory(func() (*counter, func(), error) { return nil, nil, nil }); err == nil {
+		t.Error("expected error for factory constructor returning cleanup function")
+	}
+}
+
+func TestContainerLazyInterfaceFirstMatch(t *testing.T) {
+	c := NewContainer()
+	c.Add(&english{name: "a"})
+	lazy, err := NewLazy(func() greeter { return &german{name: "b"} })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(lazy)
+
+	// interface bound later does not take precedence over earlier implementation
+	v, err := c.Provide(func(g greeter) string { return g.Greet() })
+	if err != nil || v != "hello a" {
+		t.Errorf("expected the first registered implementation, got %v, %v", v, err)
+	}
+}
+
+func TestContainerAlias(t *testing.T) {
+	c := NewContainer()
+	c.Add(&german{name: "a"})
+	c.Add(&english{name: "b"})
+
+	greeterType := reflect.TypeOf((*greeter)(nil)).Elem()
+	alias, err := NewAlias(greeterType, reflect.TypeOf(&english{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// memoised implementation is replaced by alias
+	if v, _ := c.Provide(func(g greeter) string { return g.Greet() }); v != "hallo a" {
+		t.Fatalf("expected the first registered implementation, got %v", v)
+	}
+	c.Add(alias)
+
+	v, err := c.Provide(func(g greeter) string { return g.Greet() })
+	if err != nil || v != "hello b" {
+		t.Errorf("expected aliased implementation, got %v, %v", v, err)
+	}
+
+	if _, err := NewAlias(greeterType, reflect.TypeOf(&counter{})); err == nil {
+		t.Error("expected error for type not implementing aliased interface")
+	}
+}
@@ -4,40 +4,170 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
 )
 
-// Container is a shortcut for []reflect.Value
-type Container []reflect.Value
+// Container holds dependency values indexed by their type.
+//
+// Values are resolved using first-match semantics: the first registered value
+// of the requested type, or the first registered value implementing the
+// requested interface, is returned.
+// Interface lookups are memoised, so each interface is resolved only once.
+//
+// Container is safe for concurrent use, values can be registered
+// while other goroutines resolve them.
+type Container struct {
+	mu sync.RWMutex
+
+	values []reflect.Value
+	// types maps value type to the index of the first value of that type
+	types map[reflect.Type]int
+	// ifaces memoises interface lookups
+	ifaces *ifaceIndex
+	// named holds values registered under a name, see AddNamed
+	named map[string]*Container
+	// groups holds values registered to a group, see AddGroup
+	groups map[string]*Container
+	// scoped holds constructors invoked once per RequestScope, see RequestScoped
+	scoped []*RequestScoped
+	// decorators holds decorators applied to resolved values, see Decorator
+	decorators []*Decorator
+	// bindings maps indexes of values resolved on demand to their bindings,
+	// eg. Lazy, Factory and Alias. Zero values of binding types are held in values.
+	bindings map[int]binding
+	// shared is true when values and indexes are shared with cloned containers.
+	// Shared data is copied before the first write.
+	shared bool
+	// resolving holds Lazy values created by constructors invoked by the container,
+	// so dependency cycles between them are reported instead of waiting for themselves
+	resolving []*Lazy
+}
+
+// ifaceIndex memoises interface lookups, -1 marks interfaces without implementation.
+// Index is shared by cloned containers, so it is guarded by its own mutex.
+type ifaceIndex struct {
+	mu sync.Mutex
+	m  map[reflect.Type]int
+}
+
+func newIfaceIndex() *ifaceIndex {
+	return &ifaceIndex{m: map[reflect.Type]int{}}
+}
 
 // NewContainer returns new empty Container
-func NewContainer() Container {
-	return Container{}
+func NewContainer() *Container {
+	return &Container{
+		types:    map[reflect.Type]int{},
+		ifaces:   newIfaceIndex(),
+		named:    map[string]*Container{},
+		groups:   map[string]*Container{},
+		bindings: map[int]binding{},
+	}
+}
+
+// newContainerOf returns new Container with given values
+func newContainerOf(values []reflect.Value) *Container {
+	c := NewContainer()
+	for _, v := range values {
+		c.AddValue(v)
+	}
+	return c
 }
 
-// Clone returns a copy of the current value
-func (c Container) Clone() Container {
-	if n := len(c); n > 0 {
-		values := make(Container, n, n)
-		copy(values, c)
-		return values
+// Clone returns a copy of the current container.
+// Values and indexes are shared between containers until one of them is modified.
+func (c *Container) Clone() *Container {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.shared = true
+	return &Container{
+		// limit capacity so append on any of the containers does not override shared values
+		values:   c.values[:len(c.values):len(c.values)],
+		types:    c.types,
+		ifaces:   c.ifaces,
+		named:    c.named,
+		groups:   c.groups,
+		bindings: c.bindings,
+		scoped:   c.scoped[:len(c.scoped):len(c.scoped)],
+
+		decorators: c.decorators[:len(c.decorators):len(c.decorators)],
+		shared:     true,
 	}
-	return NewContainer()
 }
 
 // CloneWithFieldsOf will return a copy of the current container
 // with provided struct fields that are filled(non-zero) by the caller
-func (c Container) CloneWithFieldsOf(i interface{}) Container {
+func (c *Container) CloneWithFieldsOf(i interface{}) *Container {
 	values := c.Clone()
 
 	// add the manual filled fields to the dependencies.
-	filledFieldValues := LookupNonZeroFieldsValues(ValueOf(i), true)
-	values = append(values, filledFieldValues...)
+	for _, v := range LookupNonZeroFieldsValues(ValueOf(i), true) {
+		values.AddValue(v)
+	}
 	return values
 }
 
-// Len returns Length of current Container slice
-func (c Container) Len() int {
-	return len(c)
+// Len returns number of values in Container
+func (c *Container) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.values)
+}
+
+// Values returns copy of values registered to Container in registration order.
+// Values resolved on demand, eg. Lazy values, are returned as zero values of their type.
+func (c *Container) Values() []reflect.Value {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	values := make([]reflect.Value, len(c.values))
+	copy(values, c.values)
+	return values
+}
+
+// detach copies shared indexes before container is modified.
+// Container has to be locked for writing.
+func (c *Container) detach() {
+	if !c.shared {
+		return
+	}
+
+	types := make(map[reflect.Type]int, len(c.types))
+	for k, v := range c.types {
+		types[k] = v
+	}
+	ifaces := newIfaceIndex()
+	c.ifaces.mu.Lock()
+	for k, v := range c.ifaces.m {
+		ifaces.m[k] = v
+	}
+	c.ifaces.mu.Unlock()
+
+	named := make(map[string]*Container, len(c.named))
+	for k, v := range c.named {
+		named[k] = v.Clone()
+	}
+	groups := make(map[string]*Container, len(c.groups))
+	for k, v := range c.groups {
+		groups[k] = v.Clone()
+	}
+	bindings := make(map[int]binding, len(c.bindings))
+	for k, v := range c.bindings {
+		bindings[k] = v
+	}
+
+	c.values = c.values[:len(c.values):len(c.values)]
+	c.scoped = c.scoped[:len(c.scoped):len(c.scoped)]
+	c.decorators = c.decorators[:len(c.decorators):len(c.decorators)]
+	c.types = types
+	c.ifaces = ifaces
+	c.named = named
+	c.groups = groups
+	c.bindings = bindings
+	c.shared = false
 }
 
 // Add adds values as dependencies, if the struct's fields
@@ -50,11 +180,154 @@ func (c *Container) Add(value interface{}) {
 // AddValue adds values as dependencies, if the struct's fields
 // or the function's input arguments needs them, they will be defined as
 // bindings (at build-time) and they will be used (at serve-time).
+//
+// RequestScoped constructors are registered as bindings resolved once per RequestScope
+// and Decorators are applied to values resolved afterwards.
 func (c *Container) AddValue(val reflect.Value) {
 	if !goodVal(val) {
 		return
 	}
-	*c = append(*c, val)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addValue(val)
+}
+
+// addValue adds value to the container, container has to be locked for writing
+func (c *Container) addValue(val reflect.Value) {
+	c.detach()
+
+	switch val.Type() {
+	case requestScopedType:
+		c.scoped = append(c.scoped, val.Interface().(*RequestScoped))
+		return
+	case decoratorType:
+		c.decorators = append(c.decorators, val.Interface().(*Decorator))
+		return
+	}
+
+	idx := len(c.values)
+	if val.Type().Implements(bindingType) {
+		b := val.Interface().(binding)
+		c.bindings[idx] = b
+		val = reflect.Zero(b.Type())
+	}
+	c.values = append(c.values, val)
+
+	typ := val.Type()
+	if _, ok := c.types[typ]; !ok {
+		c.types[typ] = idx
+	}
+
+	// interfaces without implementation may be implemented by new value
+	c.ifaces.mu.Lock()
+	for iface, i := range c.ifaces.m {
+		if i < 0 && typ.Implements(iface) {
+			c.ifaces.m[iface] = idx
+		}
+	}
+	// new alias may take precedence over memoised implementation
+	if _, alias := c.bindings[idx].(*Alias); alias {
+		delete(c.ifaces.m, typ)
+	}
+	c.ifaces.mu.Unlock()
+}
+
+// AddNamed adds value as dependency registered under given name.
+//
+// Named values are resolved only by name, using `inject:"name"` struct tag
+// on struct fields or on fields of constructor parameter structs embedding In.
+// Values registered by Add are never resolved by name and vice versa.
+func (c *Container) AddNamed(name string, value interface{}) {
+	val := ValueOf(value)
+	if !goodVal(val) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.detach()
+	nc, ok := c.named[name]
+	if !ok {
+		nc = NewContainer()
+		c.named[name] = nc
+	}
+	nc.AddValue(val)
+}
+
+// HasNamed returns true if value of the same type as "value"
+// is registered under given name
+func (c *Container) HasNamed(name string, value interface{}) bool {
+	return c.exists(reflect.TypeOf(value), name)
+}
+
+// Names returns sorted names under which values assignable to given type are registered
+func (c *Container) Names(typ reflect.Type) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var names []string
+	for name, nc := range c.named {
+		if nc.valueTypeExists(typ) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namedContainer returns container of values registered under given name
+func (c *Container) namedContainer(name string) (*Container, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	nc, ok := c.named[name]
+	return nc, ok
+}
+
+// resolve returns value assignable to given type registered under given name.
+// Values registered without name are resolved for empty name.
+// Error is returned when value resolved on demand can not be created.
+func (c *Container) resolve(typ reflect.Type, name string) (reflect.Value, bool, error) {
+	if name == "" {
+		return c.lookup(typ)
+	}
+
+	nc, ok := c.namedContainer(name)
+	if !ok {
+		return reflect.Value{}, false, nil
+	}
+	return nc.lookup(typ)
+}
+
+// resolvingWith returns clone of the container used to create Lazy value
+func (c *Container) resolvingWith(l *Lazy) *Container {
+	rc := c.Clone()
+	rc.resolving = append(c.resolving[:len(c.resolving):len(c.resolving)], l)
+	return rc
+}
+
+// isResolving returns true when given Lazy value is being created by the container
+func (c *Container) isResolving(l *Lazy) bool {
+	for _, r := range c.resolving {
+		if r == l {
+			return true
+		}
+	}
+	return false
+}
+
+// exists returns true if value assignable to given type is registered under given name,
+// values resolved on demand are not created
+func (c *Container) exists(typ reflect.Type, name string) bool {
+	if name == "" {
+		return c.valueTypeExists(typ)
+	}
+
+	nc, ok := c.namedContainer(name)
+	return ok && nc.valueTypeExists(typ)
 }
 
 // Remove unbinds a binding value based on the type,
@@ -68,45 +341,139 @@ func (c *Container) Remove(value interface{}, n int) bool {
 }
 
 func (c *Container) remove(typ reflect.Type, n int) (ok bool) {
-	input := *c
-	for i, in := range input {
-		if equalTypes(in.Type(), typ) {
+	if n <= 0 {
+		n = 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make([]reflect.Value, 0, len(c.values))
+	bindings := map[int]binding{}
+	for i, in := range c.values {
+		if n > 0 && equalTypes(in.Type(), typ) {
 			ok = true
-			input = input[:i+copy(input[i:], input[i+1:])]
-			if n > 1 {
-				continue
-			}
-			break
+			n--
+			continue
 		}
+		if b, exists := c.bindings[i]; exists {
+			bindings[len(values)] = b
+		}
+		values = append(values, in)
+	}
+
+	if !ok {
+		return
 	}
 
-	*c = input
+	// removal shifts value indexes, so indexes are rebuilt.
+	// named and group containers are still shared with clones, so they are detached
+	c.detach()
+	c.values = values
+	c.types = make(map[reflect.Type]int, len(values))
+	c.ifaces = newIfaceIndex()
+	c.bindings = bindings
+	for i, v := range values {
+		if _, exists := c.types[v.Type()]; !exists {
+			c.types[v.Type()] = i
+		}
+	}
 
 	return
 }
 
 // Has returns true if a binder responsible to
 // bind and return a type of "typ" is already registered to this controller.
-func (c Container) Has(value interface{}) bool {
+func (c *Container) Has(value interface{}) bool {
 	return c.valueTypeExists(reflect.TypeOf(value))
 }
 
-func (c Container) valueTypeExists(typ reflect.Type) bool {
-	for _, in := range c {
-		if equalTypes(in.Type(), typ) {
-			return true
+func (c *Container) valueTypeExists(typ reflect.Type) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.indexOf(typ) >= 0
+}
+
+func (c *Container) getTypeVal(typ reflect.Type) (reflect.Value, bool) {
+	v, ok, err := c.lookup(typ)
+	return v, ok && err == nil
+}
+
+// lookup returns the first value assignable to given type,
+// values resolved on demand are created by their bindings.
+//
+// Bindings and decorators are invoked without holding the lock,
+// so they can resolve their own dependencies from the container.
+func (c *Container) lookup(typ reflect.Type) (reflect.Value, bool, error) {
+	c.mu.RLock()
+	i := c.indexOf(typ)
+	if i < 0 {
+		c.mu.RUnlock()
+		return reflect.Value{}, false, nil
+	}
+	v, b, decorators := c.values[i], c.bindings[i], c.decorators
+	c.mu.RUnlock()
+
+	var err error
+	if b != nil {
+		v, err = b.resolve(c)
+	}
+	if err == nil && len(decorators) > 0 {
+		v, err = c.decorate(decorators, typ, b, v)
+	}
+	return v, err == nil, err
+}
+
+// indexOf returns index of the first value assignable to given type or -1.
+// Container has to be locked for reading.
+func (c *Container) indexOf(typ reflect.Type) int {
+	if typ == nil {
+		return -1
+	}
+
+	if typ.Kind() != reflect.Interface {
+		if i, ok := c.types[typ]; ok {
+			return i
 		}
+		return -1
 	}
-	return false
+
+	c.ifaces.mu.Lock()
+	defer c.ifaces.mu.Unlock()
+
+	if i, ok := c.ifaces.m[typ]; ok {
+		return i
+	}
+
+	// memoised result is valid for all containers sharing the values
+	idx := c.aliasOf(typ)
+	if idx < 0 {
+		for i, in := range c.values {
+			if equalTypes(in.Type(), typ) {
+				idx = i
+				break
+			}
+		}
+	}
+	c.ifaces.m[typ] = idx
+	return idx
 }
 
-func (c Container) getTypeVal(typ reflect.Type) (reflect.Value, bool) {
-	for _, in := range c {
-		if equalTypes(in.Type(), typ) {
-			return in, true
+// aliasOf returns index of the first Alias of given interface type or -1,
+// aliases take precedence over other implementations of the interface.
+// Container has to be locked for reading.
+func (c *Container) aliasOf(typ reflect.Type) int {
+	i, ok := c.types[typ]
+	if !ok {
+		return -1
+	}
+	for ; i < len(c.values); i++ {
+		if _, alias := c.bindings[i].(*Alias); alias && c.values[i].Type() == typ {
+			return i
 		}
 	}
-	return reflect.Value{}, false
+	return -1
 }
 
 // AddOnce binds a value to the controller's field with the same type,
@@ -119,19 +486,18 @@ func (c *Container) AddOnce(value interface{}) bool {
 }
 
 func (c *Container) addIfNotExists(val reflect.Value) bool {
-	var (
-		typ = val.Type() // no element, raw things here.
-	)
-
 	if !goodVal(val) {
 		return false
 	}
 
-	if c.valueTypeExists(typ) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.indexOf(val.Type()) >= 0 {
 		return false
 	}
 
-	c.Add(val)
+	c.addValue(val)
 	return true
 }
 
@@ -143,7 +509,7 @@ func (c *Container) Register(value interface{}) {
 	}
 
 	// create injector
-	injector := Struct(value, *c...)
+	injector := c.CloneWithFieldsOf(value).structInjector(ValueOf(value))
 
 	// inject dependencies to value
 	injector.Inject(value)
@@ -154,36 +520,152 @@ func (c *Container) Register(value interface{}) {
 // InjectDeps accepts a destination struct and any optional context value(s),
 // and injects registered dependencies to the destination object
 func (c *Container) InjectDeps(dest interface{}, ctx ...reflect.Value) {
-	injector := Struct(dest, *c...)
+	if dest == nil {
+		return
+	}
+	injector := c.CloneWithFieldsOf(dest).structInjector(ValueOf(dest))
 	injector.Inject(dest, ctx...)
 }
 
 // Provide registers constructor function and
-// invokes it with injected values from container to constructor
+// invokes it with injected values from container to constructor.
+//
+// Constructor has to return the value and optionally an error,
+// eg. func(...) T, func(...) (T, error) or func(...) (T, func(), error).
+// Cleanup function returned by constructor is ignored, use
+// ProvideWithCleanup when constructor returns cleanup function.
 func (c *Container) Provide(constructor interface{}) (interface{}, error) {
+	val, _, err := c.ProvideWithCleanup(constructor)
+	return val, err
+}
 
+// ProvideWithCleanup registers constructor function and
+// invokes it with injected values from container to constructor.
+// Fields of created struct pointers tagged with `inject` struct tag
+// are injected, see InjectFields.
+// It returns the value, cleanup function and error returned by constructor.
+// Cleanup function is nil when constructor does not return one.
+func (c *Container) ProvideWithCleanup(constructor interface{}) (interface{}, func(), error) {
 	typ := reflect.TypeOf(constructor)
 	if typ == nil {
-		return nil, errors.New("can't provide an untyped nil")
+		return nil, nil, errors.New("can't provide an untyped nil")
 	}
 
 	if typ.Kind() != reflect.Func {
-		return nil, fmt.Errorf("must provide constructor function, got %v (type %v)", constructor, typ)
+		return nil, nil, fmt.Errorf("must provide constructor function, got %v (type %v)", constructor, typ)
+	}
+
+	fn := reflect.ValueOf(constructor)
+	if err := validateConstructor(typ); err != nil {
+		return nil, nil, fmt.Errorf("invalid constructor %s (%s). Error: %w", funcName(fn), funcLocation(fn), err)
 	}
 
+	v, cleanup, err := c.call(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v.Interface(), cleanup, nil
+}
+
+// call invokes constructor function with parameters resolved from the container
+// and injects tagged fields of created value
+func (c *Container) call(fn reflect.Value) (reflect.Value, func(), error) {
+	typ := fn.Type()
 	in := make([]reflect.Value, typ.NumIn())
 
 	for i := 0; i < typ.NumIn(); i++ {
-		t := typ.In(i)
-		if v, ok := c.getTypeVal(t); ok {
-			in[i] = v
-		} else {
-			return nil, fmt.Errorf("Unable to provide constructor, parameter %s is nil for constructor %#v", t.Name(), constructor)
+		v, err := c.resolveParam(typ.In(i))
+		if err != nil {
+			err.Constructor = funcName(fn)
+			err.Location = funcLocation(fn)
+			return reflect.Value{}, nil, err
+		}
+		in[i] = v
+	}
+	out := fn.Call(in)
+
+	// cleanup function is ignored when constructor fails
+	if last := out[len(out)-1]; len(out) > 1 && !last.IsNil() {
+		return reflect.Value{}, nil, fmt.Errorf("constructor %s (%s) failed. Error: %w", funcName(fn), funcLocation(fn), last.Interface().(error))
+	}
+
+	var cleanup func()
+	if len(out) == 3 && !out[1].IsNil() {
+		cleanup = out[1].Interface().(func())
+	}
+
+	// inject fields tagged with `inject` struct tag
+	if err := c.InjectFields(out[0].Interface()); err != nil {
+		if cleanup != nil {
+			cleanup()
 		}
+		if depErr, ok := err.(*DependencyError); ok {
+			depErr.Constructor = funcName(fn)
+			depErr.Location = funcLocation(fn)
+		}
+		return reflect.Value{}, nil, err
+	}
+
+	return out[0], cleanup, nil
+}
+
+// resolveParam resolves constructor parameter of given type.
+// Parameter structs embedding In are created with fields resolved from container.
+func (c *Container) resolveParam(t reflect.Type) (reflect.Value, *DependencyError) {
+	if isParamStruct(t) {
+		return c.resolveParamStruct(t)
 	}
-	val := reflect.ValueOf(constructor).Call(in)
 
-	return val[0].Interface(), nil
+	v, ok, err := c.resolveDependency(Dependency{Type: t})
+	if !ok {
+		return reflect.Value{}, &DependencyError{Type: t, Available: c.Names(t), Err: err}
+	}
+	return v, nil
+}
+
+// resolveParamStruct creates parameter struct and resolves its fields by type and name
+func (c *Container) resolveParamStruct(t reflect.Type) (reflect.Value, *DependencyError) {
+	ps := reflect.New(t).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type == inType {
+			continue
+		}
+
+		name, optional := parseInjectTag(f.Tag.Get(injectTag))
+		v, ok, err := c.resolveDependency(Dependency{Type: f.Type, Name: name, Group: f.Tag.Get(groupTag)})
+		if !ok && err == nil && optional && f.PkgPath == "" {
+			continue
+		}
+		if !ok || f.PkgPath != "" {
+			return reflect.Value{}, &DependencyError{
+				Type:      f.Type,
+				Name:      name,
+				Field:     t.String() + "." + f.Name,
+				Available: c.Names(f.Type),
+				Err:       err,
+			}
+		}
+		ps.Field(i).Set(v)
+	}
+	return ps, nil
+}
+
+// validateConstructor checks constructor function return values
+func validateConstructor(typ reflect.Type) error {
+	switch typ.NumOut() {
+	case 1:
+		return nil
+	case 2:
+		if typ.Out(1) == errorType {
+			return nil
+		}
+	case 3:
+		if typ.Out(1) == cleanupType && typ.Out(2) == errorType {
+			return nil
+		}
+	}
+	return fmt.Errorf("constructor has to return T, (T, error) or (T, func(), error), got %s", typ)
 }
 
 // ProvideAndRegister registers constructor function and
@@ -0,0 +1,280 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type english struct{ name string }
+
+func (e *english) Greet() string { return "hello " + e.name }
+
+type german struct{ name string }
+
+func (g *german) Greet() string { return "hallo " + g.name }
+
+type counter struct{ n int }
+
+func TestContainerFirstMatch(t *testing.T) {
+	c := NewContainer()
+	c.Add(&counter{n: 1})
+	c.Add(&german{name: "a"})
+	c.Add(&english{name: "b"})
+	c.Add(&counter{n: 2})
+
+	v, ok := c.getTypeVal(reflect.TypeOf(&counter{}))
+	if !ok || v.Interface().(*counter).n != 1 {
+		t.Errorf("expected first counter value, got %v", v)
+	}
+
+	g, ok := c.getTypeVal(reflect.TypeOf((*greeter)(nil)).Elem())
+	if !ok || g.Interface().(greeter).Greet() != "hallo a" {
+		t.Errorf("expected first greeter implementation, got %v", g)
+	}
+
+	if c.Has(&struct{}{}) {
+		t.Error("unexpected value for unregistered type")
+	}
+}
+
+func TestContainerInterfaceMemo(t *testing.T) {
+	c := NewContainer()
+	iface := reflect.TypeOf((*greeter)(nil)).Elem()
+
+	if c.valueTypeExists(iface) {
+		t.Fatal("unexpected greeter implementation")
+	}
+
+	// negative memo has to be invalidated by new implementation
+	c.Add(&english{name: "a"})
+	if v, ok := c.getTypeVal(iface); !ok || v.Interface().(greeter).Greet() != "hello a" {
+		t.Fatalf("expected english greeter, got %v", v)
+	}
+
+	// positive memo keeps first-match semantics
+	c.Add(&german{name: "b"})
+	if v, _ := c.getTypeVal(iface); v.Interface().(greeter).Greet() != "hello a" {
+		t.Errorf("expected english greeter, got %v", v)
+	}
+
+	// removal resets memo
+	c.Remove(&english{}, 1)
+	if v, ok := c.getTypeVal(iface); !ok || v.Interface().(greeter).Greet() != "hallo b" {
+		t.Errorf("expected german greeter after removal, got %v", v)
+	}
+}
+
+func TestContainerCloneCopyOnWrite(t *testing.T) {
+	parent := NewContainer()
+	parent.Add(&counter{n: 1})
+
+	child := parent.Clone()
+	sibling := parent.Clone()
+
+	child.Add(&english{name: "child"})
+	parent.Add(&german{name: "parent"})
+
+	if child.Len() != 2 || parent.Len() != 2 || sibling.Len() != 1 {
+		t.Fatalf("wrong container lengths: parent %d, child %d, sibling %d", parent.Len(), child.Len(), sibling.Len())
+	}
+
+	iface := reflect.TypeOf((*greeter)(nil)).Elem()
+	if v, _ := child.getTypeVal(iface); v.Interface().(greeter).Greet() != "hello child" {
+		t.Errorf("child resolved wrong greeter: %v", v)
+	}
+	if v, _ := parent.getTypeVal(iface); v.Interface().(greeter).Greet() != "hallo parent" {
+		t.Errorf("parent resolved wrong greeter: %v", v)
+	}
+	if sibling.valueTypeExists(iface) {
+		t.Error("sibling sees values added after clone")
+	}
+	if !sibling.Has(&counter{}) {
+		t.Error("sibling does not see values added before clone")
+	}
+}
+
+func TestContainerRemove(t *testing.T) {
+	c := NewContainer()
+	c.Add(&counter{n: 1})
+	c.Add(&english{name: "a"})
+	c.Add(&counter{n: 2})
+	c.Add(&counter{n: 3})
+
+	if !c.Remove(&counter{}, 2) {
+		t.Fatal("expected values to be removed")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("wrong container length: %d", c.Len())
+	}
+	v, _ := c.getTypeVal(reflect.TypeOf(&counter{}))
+	if v.Interface().(*counter).n != 3 {
+		t.Errorf("expected last counter to remain, got %v", v.Interface())
+	}
+	if c.Remove(&german{}, 1) {
+		t.Error("unexpected removal of unregistered type")
+	}
+}
+
+func TestContainerInjectDeps(t *testing.T) {
+	type target struct {
+		Greeter greeter
+		Counter *counter
+		Other   *german
+	}
+
+	c := NewContainer()
+	c.Add(&counter{n: 7})
+	c.Add(&english{name: "x"})
+
+	dest := &target{}
+	c.InjectDeps(dest)
+
+	if dest.Counter == nil || dest.Counter.n != 7 {
+		t.Errorf("counter field is not injected: %v", dest.Counter)
+	}
+	if dest.Greeter == nil || dest.Greeter.Greet() != "hello x" {
+		t.Errorf("greeter field is not injected: %v", dest.Greeter)
+	}
+	if dest.Other != nil {
+		t.Errorf("unexpected value for other field: %v", dest.Other)
+	}
+}
+
+func BenchmarkContainerProvide(b *testing.B) {
+	c := NewContainer()
+	for i := 0; i < 500; i++ {
+		c.Add(fmt.Sprintf("value-%d", i))
+		c.Add(&counter{n: i})
+	}
+	c.Add(&english{name: "bench"})
+
+	ctor := func(g greeter, cnt *counter, s string) *german { return &german{} }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Provide(ctor); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestContainerNamed(t *testing.T) {
+	c := NewContainer()
+	c.Add(&counter{n: 1})
+	c.AddNamed("replica", &counter{n: 2})
+	c.AddNamed("archive", &counter{n: 3})
+
+	type params struct {
+		In
+		Primary *counter
+		Replica *counter `inject:"replica"`
+	}
+
+	v, err := c.Provide(func(p params) []int { return []int{p.Primary.n, p.Replica.n} })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := v.([]int); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("wrong resolved values: %v", got)
+	}
+
+	type target struct {
+		Primary *counter
+		Archive *counter `inject:"archive"`
+	}
+	dest := &target{}
+	c.InjectDeps(dest)
+	if dest.Primary.n != 1 || dest.Archive.n != 3 {
+		t.Errorf("wrong injected values: primary %d, archive %d", dest.Primary.n, dest.Archive.n)
+	}
+
+	if !c.HasNamed("replica", &counter{}) || c.HasNamed("primary", &counter{}) {
+		t.Error("wrong HasNamed result")
+	}
+}
+
+func TestContainerNamedMissing(t *testing.T) {
+	c := NewContainer()
+	c.AddNamed("replica", &counter{n: 2})
+	c.AddNamed("archive", &counter{n: 3})
+
+	type params struct {
+		In
+		Backup *counter `inject:"backup"`
+	}
+
+	_, err := c.Provide(func(p params) int { return 0 })
+	depErr, ok := err.(*DependencyError)
+	if !ok {
+		t.Fatalf("expected dependency error, got: %v", err)
+	}
+	if depErr.Name != "backup" || !reflect.DeepEqual(depErr.Available, []string{"archive", "replica"}) {
+		t.Errorf("wrong dependency error: %#v", depErr)
+	}
+
+	// named values are not resolved by type only
+	if _, err := c.Provide(func(*counter) int { return 0 }); err == nil {
+		t.Error("expected error for unnamed dependency")
+	}
+}
+
+func TestContainerConcurrentUse(t *testing.T) {
+	c := NewContainer()
+	c.Add(&english{name: "a"})
+
+	var calls int32
+	lazy, err := NewLazy(func(g greeter) *german {
+		atomic.AddInt32(&calls, 1)
+		return &german{name: g.Greet()}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(lazy)
+
+	type cloned struct{ n int }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			clone := c.Clone()
+			clone.Add(&cloned{n: i})
+			clone.AddNamed("replica", &counter{n: i})
+
+			c.AddOnce(&counter{n: i})
+			c.AddNamed(fmt.Sprintf("counter-%d", i), &counter{n: i})
+
+			if _, err := c.Provide(func(g *german, e *english) string { return g.Greet() + e.Greet() }); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if v, err := clone.Provide(func(c *cloned) int { return c.n }); err != nil || v.(int) != i {
+				t.Errorf("wrong clone value: %v, %v", v, err)
+			}
+			if !clone.Remove(&cloned{}, 1) {
+				t.Error("expected clone value to be removed")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("lazy constructor should be invoked once, invoked %d times", calls)
+	}
+	if !c.Has(&counter{}) || c.Len() != 3 {
+		t.Errorf("wrong number of container values: %d", c.Len())
+	}
+	if names := c.Names(reflect.TypeOf(&counter{})); len(names) != 50 {
+		t.Errorf("wrong number of named values: %d", len(names))
+	}
+}
@@ -0,0 +1,134 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Decorator wraps values of a type registered to the Container,
+// eg. to add caching, metrics or retries around a repository interface.
+//
+// Decorator is a function which receives the value as the first parameter
+// and returns the decorated value of the same type, eg. func(inner Repo, m *Metrics) Repo
+// or func(inner Repo) (Repo, error). Other parameters are resolved from the container.
+//
+// Decorators are applied when the value is resolved by its exact type and to elements
+// of slices and groups of that type, in order of their registration, so the first registered decorator wraps the value
+// and the last registered decorator is seen by consumers.
+type Decorator struct {
+	fn          reflect.Value
+	constructor *Constructor
+
+	mu sync.Mutex
+	// decorated memoises decorated values by the values they wrap
+	decorated map[interface{}]reflect.Value
+}
+
+var decoratorType = reflect.TypeOf(&Decorator{})
+
+// NewDecorator creates Decorator. Created value has to be added
+// to the Container in order to be applied.
+func NewDecorator(decorator interface{}) (*Decorator, error) {
+	c, err := Inspect(decorator)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeOf(decorator)
+	if typ.NumIn() == 0 || typ.In(0) != c.Result || isParamStruct(typ.In(0)) {
+		return nil, fmt.Errorf("invalid decorator %s (%s). Error: decorator has to receive decorated `%s` as the first parameter", c.Name, c.Location, c.Result)
+	}
+	for i := 1; i < typ.NumIn(); i++ {
+		if typ.In(i) == c.Result {
+			return nil, fmt.Errorf("invalid decorator %s (%s). Error: decorator can receive decorated `%s` only once", c.Name, c.Location, c.Result)
+		}
+	}
+	if typ.NumOut() == 3 {
+		return nil, fmt.Errorf("invalid decorator %s (%s). Error: decorator can not return cleanup function", c.Name, c.Location)
+	}
+
+	return &Decorator{
+		fn:          reflect.ValueOf(decorator),
+		constructor: c,
+		decorated:   map[interface{}]reflect.Value{},
+	}, nil
+}
+
+// Type returns the type of decorated values
+func (d *Decorator) Type() reflect.Type {
+	return d.constructor.Result
+}
+
+// apply returns decorated value, decorated values are memoised when "memo" is true.
+//
+// Decorator parameters are resolved without holding the lock, so decorators
+// may depend on values decorated by other decorators.
+func (d *Decorator) apply(c *Container, inner reflect.Value, memo bool) (reflect.Value, error) {
+	// values of interface types are memoised by their dynamic value, when it is comparable
+	var key interface{}
+	if memo && inner.IsValid() {
+		if k := inner.Interface(); k != nil && reflect.TypeOf(k).Comparable() {
+			key = k
+		}
+	}
+
+	if key != nil {
+		d.mu.Lock()
+		v, ok := d.decorated[key]
+		d.mu.Unlock()
+		if ok {
+			return v, nil
+		}
+	}
+
+	typ := d.fn.Type()
+	in := make([]reflect.Value, typ.NumIn())
+	in[0] = inner
+	for i := 1; i < len(in); i++ {
+		v, err := c.resolveParam(typ.In(i))
+		if err != nil {
+			err.Constructor = d.constructor.Name
+			err.Location = d.constructor.Location
+			return reflect.Value{}, err
+		}
+		in[i] = v
+	}
+
+	out := d.fn.Call(in)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("decorator %s (%s) failed. Error: %w", d.constructor.Name, d.constructor.Location, out[1].Interface().(error))
+	}
+
+	if key == nil {
+		return out[0], nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// value decorated concurrently wins, so all consumers receive the same value
+	if v, ok := d.decorated[key]; ok {
+		return v, nil
+	}
+	d.decorated[key] = out[0]
+	return out[0], nil
+}
+
+// decorate applies given decorators registered for given type to the value,
+// "b" is the binding of the value or nil
+func (c *Container) decorate(decorators []*Decorator, typ reflect.Type, b binding, v reflect.Value) (reflect.Value, error) {
+	// factory values are new on every resolution, so they are not memoised
+	_, factory := b.(*Factory)
+
+	for _, d := range decorators {
+		if d.Type() != typ {
+			continue
+		}
+		var err error
+		if v, err = d.apply(c, v, !factory); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,143 @@
+package di
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type politeGreeter struct {
+	inner  greeter
+	prefix string
+}
+
+func (p *politeGreeter) Greet() string { return p.prefix + " " + p.inner.Greet() }
+
+func TestContainerDecorators(t *testing.T) {
+	c := NewContainer()
+	c.Add(&english{name: "b"})
+
+	for _, prefix := range []string{"dear", "oh"} {
+		prefix := prefix
+		d, err := NewDecorator(func(inner greeter, cnt *counter) greeter {
+			return &politeGreeter{inner: inner, prefix: prefix}
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c.Add(d)
+	}
+	c.Add(&counter{})
+
+	var first greeter
+	for i := 0; i < 2; i++ {
+		v, err := c.Provide(func(g greeter) greeter { return g })
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := v.(greeter).Greet(); got != "oh dear hello b" {
+			t.Errorf("wrong decorated value: %s", got)
+		}
+		if first == nil {
+			first = v.(greeter)
+		} else if first != v {
+			t.Error("decorated value should be memoised")
+		}
+	}
+
+	// decorators are applied only to values resolved by decorated type
+	v, _ := c.Provide(func(e *english) string { return e.Greet() })
+	if v != "hello b" {
+		t.Errorf("concrete type should not be decorated: %v", v)
+	}
+}
+
+func TestContainerDecoratorErrors(t *testing.T) {
+	errDecorate := errors.New("decoration failed")
+
+	c := NewContainer()
+	c.Add(&english{name: "b"})
+	d, _ := NewDecorator(func(inner greeter) (greeter, error) { return nil, errDecorate })
+	c.Add(d)
+
+	if _, err := c.Provide(func(g greeter) int { return 1 }); !errors.Is(err, errDecorate) {
+		t.Errorf("expected decorator error, got: %v", err)
+	}
+
+	for _, fn := range []interface{}{
+		func() greeter { return nil },
+		func(c *counter) greeter { return nil },
+		func(a, b greeter) greeter { return a },
+	} {
+		if _, err := NewDecorator(fn); err == nil {
+			t.Errorf("expected invalid decorator error for %s", reflect.TypeOf(fn))
+		}
+	}
+}
+
+func TestContainerDecoratorsMultiple(t *testing.T) {
+	c := NewContainer()
+	c.Add(&english{name: "a"})
+	c.Add(&german{name: "b"})
+	c.AddGroup("greeters", &english{name: "c"})
+	d, err := NewDecorator(func(inner greeter) greeter {
+		return &politeGreeter{inner: inner, prefix: "dear"}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(d)
+
+	type params struct {
+		In
+		All   []greeter
+		Group []greeter `group:"greeters"`
+	}
+
+	var got []string
+	if err := c.Invoke(func(p params) {
+		for _, g := range append(p.All, p.Group...) {
+			got = append(got, g.Greet())
+		}
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"dear hello a", "dear hallo b", "dear hello c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("slice and group values are not decorated: want %v, got %v", want, got)
+	}
+}
+
+// chainGreeter is not comparable, so it can not be used as a map key
+type chainGreeter struct {
+	inner    greeter
+	prefixes []string
+}
+
+func (g chainGreeter) Greet() string { return g.prefixes[0] + " " + g.inner.Greet() }
+
+func TestContainerDecoratorsUncomparable(t *testing.T) {
+	c := NewContainer()
+	c.Add(&english{name: "a"})
+	for _, prefix := range []string{"dear", "oh"} {
+		prefix := prefix
+		d, err := NewDecorator(func(inner greeter) greeter {
+			return chainGreeter{inner: inner, prefixes: []string{prefix}}
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c.Add(d)
+	}
+
+	for i := 0; i < 2; i++ {
+		v, err := c.Provide(func(g greeter) greeter { return g })
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := v.(greeter).Greet(); got != "oh dear hello a" {
+			t.Errorf("wrong decorated value: %s", got)
+		}
+	}
+}
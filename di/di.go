@@ -13,8 +13,5 @@ func Struct(s interface{}, values ...reflect.Value) *StructInjector {
 		return &StructInjector{Has: false}
 	}
 
-	return MakeStructInjector(
-		ValueOf(s),
-		Container(values).CloneWithFieldsOf(s)...,
-	)
+	return newContainerOf(values).CloneWithFieldsOf(s).structInjector(ValueOf(s))
 }
@@ -0,0 +1,56 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DependencyError is returned when constructor parameter
+// can not be resolved from the container
+type DependencyError struct {
+	// Type of the missing dependency
+	Type reflect.Type
+	// Name of the missing dependency, empty for dependencies resolved only by type
+	Name string
+	// Field of parameter struct which holds missing dependency
+	Field string
+	// Available names under which values of the Type are registered
+	Available []string
+	// Constructor is the full name of constructor function
+	Constructor string
+	// Location of constructor function in `file:line` format
+	Location string
+	// Err is the error returned when registered dependency can not be created on demand
+	Err error
+}
+
+func (e *DependencyError) Error() string {
+	var b strings.Builder
+	if e.Err != nil {
+		fmt.Fprintf(&b, "unable to create dependency `%s`", e.Type)
+	} else {
+		fmt.Fprintf(&b, "missing dependency `%s`", e.Type)
+	}
+	if e.Name != "" {
+		fmt.Fprintf(&b, " named `%s`", e.Name)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, " for field %s", e.Field)
+	}
+	if e.Constructor != "" {
+		fmt.Fprintf(&b, " for constructor %s (%s)", e.Constructor, e.Location)
+	}
+	if len(e.Available) > 0 {
+		fmt.Fprintf(&b, ", available names: %s", strings.Join(e.Available, ", "))
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ". Error: %v", e.Err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the error returned when dependency can not be created
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,111 @@
+package di
+
+import (
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// optionalTag marks soft dependencies in `inject` struct tag
+const optionalTag = "optional"
+
+// parseInjectTag returns dependency name and optional flag of `inject` struct tag value,
+// eg. `inject:""`, `inject:"name"`, `inject:"optional"` or `inject:"name,optional"`
+func parseInjectTag(tag string) (name string, optional bool) {
+	parts := strings.Split(tag, ",")
+	for _, p := range parts[1:] {
+		if p == optionalTag {
+			optional = true
+		}
+	}
+
+	name = parts[0]
+	if name == optionalTag {
+		return "", true
+	}
+	return name, optional
+}
+
+// taggedField is a struct field opted in for injection with `inject` struct tag
+type taggedField struct {
+	field reflect.StructField
+	index []int
+	dep   Dependency
+}
+
+// lookupTaggedFields returns exported and unexported fields tagged with `inject` struct tag,
+// including fields of embedded structs
+func lookupTaggedFields(elemTyp reflect.Type, parentIndex []int) (fields []taggedField) {
+	if elemTyp.Kind() != reflect.Struct {
+		return
+	}
+
+	for i, n := 0, elemTyp.NumField(); i < n; i++ {
+		f := elemTyp.Field(i)
+		index := append(append([]int{}, parentIndex...), i)
+
+		tag, ok := f.Tag.Lookup(injectTag)
+		if !ok {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				fields = append(fields, lookupTaggedFields(f.Type, index)...)
+			}
+			continue
+		}
+
+		name, optional := parseInjectTag(tag)
+		fields = append(fields, taggedField{
+			field: f,
+			index: index,
+			dep:   Dependency{Type: f.Type, Name: name, Group: f.Tag.Get(groupTag), Optional: optional},
+		})
+	}
+	return
+}
+
+// InjectFields injects dependencies to the fields of "dest" struct pointer
+// which are tagged with `inject` struct tag. Unexported tagged fields are injected as well.
+//
+// Fields are selected by type with `inject:""`, or by name with `inject:"name"`.
+// Optional dependencies, eg. `inject:"optional"` or `inject:"name,optional"`,
+// are left unchanged when they can not be resolved, otherwise DependencyError
+// naming the struct and the field is returned.
+// Fields which are already set are not changed.
+func (c *Container) InjectFields(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	elem := v.Elem()
+	for _, f := range lookupTaggedFields(elem.Type(), nil) {
+		if !elem.FieldByIndex(f.index).IsZero() {
+			continue
+		}
+
+		val, ok, err := c.resolveDependency(f.dep)
+		if !ok {
+			if f.dep.Optional && err == nil {
+				continue
+			}
+			return &DependencyError{
+				Type:      f.dep.Type,
+				Name:      f.dep.Name,
+				Field:     elem.Type().String() + "." + f.field.Name,
+				Available: c.Names(f.dep.Type),
+				Err:       err,
+			}
+		}
+		setField(elem, f.index, val)
+	}
+	return nil
+}
+
+// setField sets the value of the struct field, unexported fields are set as well.
+// Struct value has to be addressable.
+func setField(elem reflect.Value, index []int, v reflect.Value) {
+	f := elem.FieldByIndex(index)
+	if !f.CanSet() {
+		f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+	}
+	f.Set(v)
+}
@@ -0,0 +1,89 @@
+package di
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type taggedService struct {
+	Counter  *counter `inject:""`
+	greeter  greeter  `inject:"polite"`
+	cache    *english `inject:"optional"`
+	replica  *german  `inject:"replica,optional"`
+	Untagged *counter
+}
+
+func TestContainerInjectFields(t *testing.T) {
+	c := NewContainer()
+	c.Add(&counter{n: 1})
+	c.AddNamed("polite", &english{name: "sir"})
+
+	s := &taggedService{}
+	if err := c.InjectFields(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s.Counter == nil || s.Counter.n != 1 {
+		t.Errorf("exported tagged field is not injected: %+v", s.Counter)
+	}
+	if s.greeter == nil || s.greeter.Greet() != "hello sir" {
+		t.Errorf("unexported named field is not injected: %+v", s.greeter)
+	}
+	if s.cache != nil || s.replica != nil {
+		t.Error("missing optional dependencies should be left unset")
+	}
+	if s.Untagged != nil {
+		t.Error("untagged field should not be injected")
+	}
+
+	preset := &counter{n: 2}
+	s = &taggedService{Counter: preset}
+	if err := c.InjectFields(s); err != nil || s.Counter != preset {
+		t.Errorf("already set field should not be changed: %v", err)
+	}
+}
+
+func TestContainerInjectFieldsMissing(t *testing.T) {
+	c := NewContainer()
+	c.Add(&counter{n: 1})
+
+	err := c.InjectFields(&taggedService{})
+
+	var depErr *DependencyError
+	if !errors.As(err, &depErr) {
+		t.Fatalf("expected dependency error, got: %v", err)
+	}
+	if want := "missing dependency `di.greeter` named `polite` for field di.taggedService.greeter"; err.Error() != want {
+		t.Errorf("wrong error message:\nwant %s\ngot  %s", want, err)
+	}
+}
+
+func TestContainerProvideInjectsFields(t *testing.T) {
+	c := NewContainer()
+	c.AddNamed("polite", &english{name: "sir"})
+
+	_, err := c.Provide(func() *taggedService { return &taggedService{} })
+	if err == nil || !strings.Contains(err.Error(), "missing dependency `*di.counter` for field di.taggedService.Counter for constructor") {
+		t.Fatalf("expected missing field dependency error, got: %v", err)
+	}
+
+	c.Add(&counter{n: 1})
+	v, err := c.Provide(func() *taggedService { return &taggedService{} })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := v.(*taggedService); s.Counter == nil || s.greeter == nil {
+		t.Errorf("tagged fields are not injected: %+v", s)
+	}
+
+	c2 := NewContainer()
+	if _, err := c2.Provide(func(p struct {
+		In
+		Counter *counter `inject:"optional"`
+	}) int {
+		return 1
+	}); err != nil {
+		t.Errorf("optional parameter struct field should not fail: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package di
+
+import "reflect"
+
+// groupTag is the struct tag used to select group of dependencies
+const groupTag = "group"
+
+// AddGroup adds value to given group of dependencies.
+//
+// Group values are resolved only as a slice, using `group:"name"` struct tag
+// on struct fields or on fields of constructor parameter structs embedding In.
+// Slice holds all values of the group assignable to slice element type in registration order.
+//
+// type Checks struct {
+// 	di.In
+// 	Checkers []HealthChecker `group:"health"`
+// }
+func (c *Container) AddGroup(group string, value interface{}) {
+	val := ValueOf(value)
+	if !goodVal(val) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.detach()
+	gc, ok := c.groups[group]
+	if !ok {
+		gc = NewContainer()
+		c.groups[group] = gc
+	}
+	gc.AddValue(val)
+}
+
+// All returns slice of given slice type holding all values assignable
+// to its element type in registration order. Named and group values are not included.
+// Values resolved on demand which can not be created are skipped.
+func (c *Container) All(sliceType reflect.Type) reflect.Value {
+	values, _ := c.all(sliceType)
+	return values
+}
+
+// all returns slice of all values assignable to slice element type,
+// error is returned when value resolved on demand can not be created
+func (c *Container) all(sliceType reflect.Type) (reflect.Value, error) {
+	return c.collect(sliceType, c)
+}
+
+// collect returns slice of all values assignable to slice element type, decorated
+// by decorators of "dc" container registered for the element type, eg. for group values
+func (c *Container) collect(sliceType reflect.Type, dc *Container) (reflect.Value, error) {
+	elem := sliceType.Elem()
+
+	c.mu.RLock()
+	var matched []reflect.Value
+	var bindings []binding
+	for i, v := range c.values {
+		if equalTypes(v.Type(), elem) {
+			matched = append(matched, v)
+			bindings = append(bindings, c.bindings[i])
+		}
+	}
+	c.mu.RUnlock()
+
+	dc.mu.RLock()
+	decorators := dc.decorators
+	dc.mu.RUnlock()
+
+	values := reflect.MakeSlice(sliceType, 0, len(matched))
+	var firstErr error
+	for i, v := range matched {
+		var err error
+		b := bindings[i]
+		if b != nil {
+			v, err = b.resolve(c)
+		}
+		if err == nil && len(decorators) > 0 {
+			v, err = dc.decorate(decorators, elem, b, v)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		values = reflect.Append(values, v)
+	}
+	return values, firstErr
+}
+
+// resolveDependency returns value of given dependency.
+//
+// Group dependencies are resolved to all values of the group. Slices of interfaces
+// are resolved to all implementations unless the slice itself is registered.
+// Error is returned when value resolved on demand can not be created.
+func (c *Container) resolveDependency(d Dependency) (reflect.Value, bool, error) {
+	if d.Group != "" {
+		if d.Type.Kind() != reflect.Slice {
+			return reflect.Value{}, false, nil
+		}
+		c.mu.RLock()
+		gc, ok := c.groups[d.Group]
+		c.mu.RUnlock()
+		if ok {
+			v, err := gc.collect(d.Type, c)
+			return v, err == nil, err
+		}
+		return reflect.MakeSlice(d.Type, 0, 0), true, nil
+	}
+
+	if v, ok, err := c.resolve(d.Type, d.Name); ok || err != nil {
+		return v, ok, err
+	}
+
+	if d.Name == "" && isInterfaceSlice(d.Type) {
+		v, err := c.all(d.Type)
+		return v, err == nil, err
+	}
+	return reflect.Value{}, false, nil
+}
+
+// hasDependency returns true if given dependency can be resolved,
+// values resolved on demand are not created
+func (c *Container) hasDependency(d Dependency) bool {
+	if d.Group != "" {
+		return d.Type.Kind() == reflect.Slice
+	}
+	return c.exists(d.Type, d.Name) || (d.Name == "" && isInterfaceSlice(d.Type))
+}
+
+// isInterfaceSlice returns true for slices of interfaces
+func isInterfaceSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Interface
+}
@@ -0,0 +1,62 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+)
+
+type greeterParams struct {
+	In
+	All    []greeter
+	Polite []greeter `group:"polite"`
+}
+
+func TestContainerMultiBindings(t *testing.T) {
+	c := NewContainer()
+	c.Add(&german{name: "a"})
+	c.AddGroup("polite", &english{name: "sir"})
+	c.Add(&english{name: "b"})
+	c.AddGroup("polite", &german{name: "herr"})
+
+	greetings := func(greeters []greeter) (s []string) {
+		for _, g := range greeters {
+			s = append(s, g.Greet())
+		}
+		return
+	}
+
+	v, err := c.Provide(func(p greeterParams) []string {
+		return append(greetings(p.All), greetings(p.Polite)...)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"hallo a", "hello b", "hello sir", "hallo herr"}; !reflect.DeepEqual(v, want) {
+		t.Errorf("wrong multi bindings: want %v, got %v", want, v)
+	}
+
+	v, err = c.Provide(func(gs []greeter) int { return len(gs) })
+	if err != nil || v != 2 {
+		t.Errorf("expected all implementations for slice parameter, got %v, %v", v, err)
+	}
+
+	var s struct {
+		Polite []greeter `group:"polite"`
+		Empty  []greeter `group:"empty"`
+	}
+	c.InjectDeps(&s)
+	if len(s.Polite) != 2 || s.Empty == nil || len(s.Empty) != 0 {
+		t.Errorf("wrong group fields: %+v", s)
+	}
+}
+
+func TestContainerRegisteredSliceWins(t *testing.T) {
+	c := NewContainer()
+	c.Add(&german{name: "a"})
+	c.Add([]greeter{&english{name: "b"}})
+
+	v, err := c.Provide(func(gs []greeter) string { return gs[0].Greet() })
+	if err != nil || v != "hello b" {
+		t.Errorf("expected registered slice, got %v, %v", v, err)
+	}
+}
@@ -0,0 +1,120 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Dependency describes single dependency of constructor function
+type Dependency struct {
+	Type reflect.Type
+	Name string
+	// Group of the values collected to slice dependency, see AddGroup
+	Group string
+	// Optional dependencies are left unset when they can not be resolved
+	Optional bool
+	// Field of parameter struct or created struct which holds the dependency
+	Field string
+}
+
+// String returns dependency type and name
+func (d Dependency) String() string {
+	switch {
+	case d.Group != "":
+		return fmt.Sprintf("%s group `%s`", d.Type, d.Group)
+	case d.Name != "":
+		return fmt.Sprintf("%s `%s`", d.Type, d.Name)
+	}
+	return d.Type.String()
+}
+
+// Multiple returns true when dependency collects multiple values, eg. group dependencies
+// and slices of interfaces, see Container.All
+func (d Dependency) Multiple() bool {
+	return d.Group != "" || isInterfaceSlice(d.Type)
+}
+
+// Constructor holds the information about constructor function
+// required to resolve it without invoking it
+type Constructor struct {
+	// Name is the full name of constructor function
+	Name string
+	// Location of constructor function in `file:line` format
+	Location string
+	// Result is the type of the value created by constructor
+	Result reflect.Type
+	// Dependencies of constructor, fields of parameter structs embedding In
+	// and tagged fields of created struct are listed as separate dependencies
+	Dependencies []Dependency
+}
+
+// Inspect returns information about given constructor function
+func Inspect(constructor interface{}) (*Constructor, error) {
+	typ := reflect.TypeOf(constructor)
+	if typ == nil {
+		return nil, errors.New("can't inspect an untyped nil")
+	}
+
+	if typ.Kind() != reflect.Func {
+		return nil, fmt.Errorf("must provide constructor function, got %v (type %v)", constructor, typ)
+	}
+
+	fn := reflect.ValueOf(constructor)
+	if err := validateConstructor(typ); err != nil {
+		return nil, fmt.Errorf("invalid constructor %s (%s). Error: %w", funcName(fn), funcLocation(fn), err)
+	}
+
+	c := &Constructor{
+		Name:     funcName(fn),
+		Location: funcLocation(fn),
+		Result:   typ.Out(0),
+	}
+
+	for i := 0; i < typ.NumIn(); i++ {
+		t := typ.In(i)
+		if !isParamStruct(t) {
+			c.Dependencies = append(c.Dependencies, Dependency{Type: t})
+			continue
+		}
+
+		for j := 0; j < t.NumField(); j++ {
+			f := t.Field(j)
+			if f.Anonymous && f.Type == inType {
+				continue
+			}
+			name, optional := parseInjectTag(f.Tag.Get(injectTag))
+			c.Dependencies = append(c.Dependencies, Dependency{Type: f.Type, Name: name, Group: f.Tag.Get(groupTag), Optional: optional, Field: t.String() + "." + f.Name})
+		}
+	}
+
+	// fields of created struct tagged with `inject` struct tag
+	if r := c.Result; r.Kind() == reflect.Ptr {
+		for _, f := range lookupTaggedFields(r.Elem(), nil) {
+			f.dep.Field = r.Elem().String() + "." + f.field.Name
+			c.Dependencies = append(c.Dependencies, f.dep)
+		}
+	}
+
+	return c, nil
+}
+
+// Provides returns true if value created by constructor and registered
+// under given name and group can be resolved as given dependency.
+// Values of multiple dependencies are provided by all matching constructors.
+func (c *Constructor) Provides(d Dependency, name, group string) bool {
+	if d.Multiple() {
+		return d.Group == group && name == "" && equalTypes(c.Result, d.Type.Elem())
+	}
+	if d.Name != name || group != "" {
+		return false
+	}
+	return equalTypes(c.Result, d.Type)
+}
+
+// CanResolve returns true if dependency can be resolved from the container.
+// Multiple dependencies can always be resolved, possibly to an empty slice.
+// Values resolved on demand, eg. Lazy values, are not created.
+func (c *Container) CanResolve(d Dependency) bool {
+	return c.hasDependency(d)
+}
@@ -0,0 +1,58 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Invoke calls function with parameters resolved from the container
+// the same way as Provide resolves constructor parameters.
+//
+// Function can return nothing or an error, eg. func(...) or func(...) error.
+// Variadic parameter receives all values assignable to its element type
+// in registration order, eg. func(db *sql.DB, checks ...HealthChecker) error.
+// Error returned by the function is returned by Invoke.
+func (c *Container) Invoke(function interface{}) error {
+	typ := reflect.TypeOf(function)
+	if typ == nil {
+		return errors.New("can't invoke an untyped nil")
+	}
+
+	if typ.Kind() != reflect.Func {
+		return fmt.Errorf("must provide function, got %v (type %v)", function, typ)
+	}
+
+	fn := reflect.ValueOf(function)
+	if typ.NumOut() > 1 || (typ.NumOut() == 1 && typ.Out(0) != errorType) {
+		return fmt.Errorf("invalid function %s (%s). Error: function can return only an error", funcName(fn), funcLocation(fn))
+	}
+
+	in := make([]reflect.Value, typ.NumIn())
+	for i := range in {
+		t := typ.In(i)
+		if typ.IsVariadic() && i == len(in)-1 {
+			in[i] = c.All(t)
+			continue
+		}
+
+		v, err := c.resolveParam(t)
+		if err != nil {
+			err.Constructor = funcName(fn)
+			err.Location = funcLocation(fn)
+			return err
+		}
+		in[i] = v
+	}
+
+	var out []reflect.Value
+	if typ.IsVariadic() {
+		out = fn.CallSlice(in)
+	} else {
+		out = fn.Call(in)
+	}
+	if len(out) == 1 && !out[0].IsNil() {
+		return out[0].Interface().(error)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package di
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestContainerInvoke(t *testing.T) {
+	c := NewContainer()
+	c.Add(&counter{n: 1})
+	c.Add(&german{name: "a"})
+	c.Add(&english{name: "b"})
+
+	var got []string
+	err := c.Invoke(func(cnt *counter, greeters ...greeter) error {
+		for _, g := range greeters {
+			got = append(got, g.Greet())
+		}
+		if cnt.n != 1 {
+			t.Errorf("wrong counter: %d", cnt.n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"hallo a", "hello b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong variadic values: want %v, got %v", want, got)
+	}
+
+	errMigrate := errors.New("migration failed")
+	if err := c.Invoke(func(*counter) error { return errMigrate }); err != errMigrate {
+		t.Errorf("expected function error, got: %v", err)
+	}
+
+	called := false
+	if err := c.Invoke(func() { called = true }); err != nil || !called {
+		t.Errorf("function without results was not invoked: %v", err)
+	}
+}
+
+func TestContainerInvokeErrors(t *testing.T) {
+	c := NewContainer()
+
+	var depErr *DependencyError
+	if err := c.Invoke(func(*counter) {}); !errors.As(err, &depErr) {
+		t.Errorf("expected dependency error, got: %v", err)
+	}
+
+	if err := c.Invoke(func() int { return 1 }); err == nil {
+		t.Error("expected invalid function error")
+	}
+
+	if err := c.Invoke(nil); err == nil {
+		t.Error("expected untyped nil error")
+	}
+}
@@ -37,6 +37,9 @@ type BindObject struct {
 
 	BindType    BindType
 	ReturnValue func([]reflect.Value) reflect.Value
+	// ResolveValue returns the value of Dynamic binding or an error when the value
+	// can not be resolved, it is used instead of ReturnValue when it is set
+	ResolveValue func([]reflect.Value) (reflect.Value, error)
 }
 
 // MakeBindObject accepts any "v" value, struct, pointer or a function
@@ -59,10 +62,20 @@ func (b *BindObject) IsAssignable(to reflect.Type) bool {
 
 // Assign sets the values to a setter, "toSetter" contains the setter, so the caller
 // can use it for multiple and different structs/functions as well.
-func (b *BindObject) Assign(ctx []reflect.Value, toSetter func(reflect.Value)) {
+// Error is returned when the value of Dynamic binding can not be resolved.
+func (b *BindObject) Assign(ctx []reflect.Value, toSetter func(reflect.Value)) error {
 	if b.BindType == Dynamic {
+		if b.ResolveValue != nil {
+			v, err := b.ResolveValue(ctx)
+			if err != nil {
+				return err
+			}
+			toSetter(v)
+			return nil
+		}
 		toSetter(b.ReturnValue(ctx))
-		return
+		return nil
 	}
 	toSetter(b.Value)
+	return nil
 }
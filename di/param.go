@@ -0,0 +1,36 @@
+package di
+
+import "reflect"
+
+// injectTag is the struct tag used to select named dependency
+const injectTag = "inject"
+
+// In is embedded into constructor parameter structs.
+// Fields of parameter struct are resolved from the container
+// and can select named dependencies with `inject:"name"` struct tag.
+// Optional dependencies tagged with `inject:"optional"` or `inject:"name,optional"`
+// are left unset when they can not be resolved.
+//
+// type DBParams struct {
+// 	di.In
+// 	Primary *sql.DB
+// 	Replica *sql.DB `inject:"replica"`
+// }
+//
+// func NewRepository(p DBParams) *Repository
+type In struct{}
+
+var inType = reflect.TypeOf(In{})
+
+// isParamStruct returns true for struct types embedding In
+func isParamStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Anonymous && f.Type == inType {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,9 @@
 package di
 
 import (
+	"fmt"
 	"reflect"
+	"runtime"
 )
 
 // EmptyIn is just an empty slice of reflect.Value.
@@ -145,6 +147,8 @@ type field struct {
 	Name   string // the actual name.
 	Index  []int  // the index of the field, slice if it's part of a embedded struct
 	CanSet bool   // is true if it's exported.
+	Inject string // the name of the dependency selected by `inject` struct tag.
+	Group  string // the group of the dependencies selected by `group` struct tag.
 
 	// this could be empty, but in our cases it's not,
 	// it's filled with the bind object (as service which means as static value)
@@ -184,11 +188,14 @@ func lookupFields(elemTyp reflect.Type, skipUnexported bool, parentIndex []int)
 			index = append(parentIndex, i)
 		}
 
+		name, _ := parseInjectTag(f.Tag.Get(injectTag))
 		fld := field{
 			Type:   f.Type,
 			Name:   f.Name,
 			Index:  index,
 			CanSet: isExported,
+			Inject: name,
+			Group:  f.Tag.Get(groupTag),
 		}
 
 		fields = append(fields, fld)
@@ -213,3 +220,26 @@ func LookupNonZeroFieldsValues(v reflect.Value, skipUnexported bool) (bindValues
 
 	return
 }
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	cleanupType = reflect.TypeOf((func())(nil))
+)
+
+// funcName returns full name of the function
+func funcName(fn reflect.Value) string {
+	if f := runtime.FuncForPC(fn.Pointer()); f != nil {
+		return f.Name()
+	}
+	return fn.Type().String()
+}
+
+// funcLocation returns function definition location in `file:line` format
+func funcLocation(fn reflect.Value) string {
+	f := runtime.FuncForPC(fn.Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	file, line := f.FileLine(f.Entry())
+	return fmt.Sprintf("%s:%d", file, line)
+}
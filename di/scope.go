@@ -0,0 +1,212 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// RequestScoped is a constructor registered to the Container which is invoked lazily,
+// at most once per RequestScope, eg. once per http request.
+//
+// Constructor parameters are resolved from RequestScope context values (eg. *http.Request),
+// from static container values and from other RequestScoped constructors.
+// RequestScoped constructors can return the same results as constructors used by Provide.
+type RequestScoped struct {
+	fn          reflect.Value
+	constructor *Constructor
+}
+
+var requestScopedType = reflect.TypeOf(&RequestScoped{})
+
+// NewRequestScoped creates RequestScoped constructor. Created value has to be added
+// to the Container in order to be resolved.
+func NewRequestScoped(constructor interface{}) (*RequestScoped, error) {
+	c, err := Inspect(constructor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RequestScoped{
+		fn:          reflect.ValueOf(constructor),
+		constructor: c,
+	}, nil
+}
+
+// Type returns the type of the value created by RequestScoped constructor
+func (s *RequestScoped) Type() reflect.Type {
+	return s.constructor.Result
+}
+
+// scopedBinding returns the first RequestScoped constructor creating values assignable to given type
+func (c *Container) scopedBinding(typ reflect.Type) *RequestScoped {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, s := range c.scoped {
+		if equalTypes(s.Type(), typ) {
+			return s
+		}
+	}
+	return nil
+}
+
+// RequestScope holds values created by RequestScoped constructors during single unit of work,
+// eg. single http request. RequestScope is safe for concurrent use.
+type RequestScope struct {
+	container *Container
+	ctx       []reflect.Value
+
+	mu       sync.Mutex
+	values   map[*RequestScoped]reflect.Value
+	cleanups []func()
+}
+
+// NewRequestScope creates new RequestScope for given context values.
+// Context values are injected to RequestScoped constructors parameters of the same type.
+func (c *Container) NewRequestScope(ctx ...interface{}) *RequestScope {
+	return &RequestScope{
+		container: c,
+		ctx:       ValuesOf(ctx),
+		values:    map[*RequestScoped]reflect.Value{},
+	}
+}
+
+// Resolve returns value assignable to given type. RequestScoped constructors are invoked
+// only when the value can not be resolved from context values or from the container.
+func (s *RequestScope) Resolve(typ reflect.Type) (reflect.Value, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.resolve(typ, nil)
+}
+
+// Close invokes cleanup functions returned by RequestScoped constructors
+// in reverse order of their creation
+func (s *RequestScope) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.cleanups) - 1; i >= 0; i-- {
+		s.cleanups[i]()
+	}
+	s.cleanups = nil
+}
+
+func (s *RequestScope) resolve(typ reflect.Type, resolving []*RequestScoped) (reflect.Value, error) {
+	for _, v := range s.ctx {
+		if equalTypes(v.Type(), typ) {
+			return v, nil
+		}
+	}
+
+	if v, ok := s.container.getTypeVal(typ); ok {
+		return v, nil
+	}
+
+	if b := s.container.scopedBinding(typ); b != nil {
+		return s.instance(b, resolving)
+	}
+
+	return reflect.Value{}, &DependencyError{Type: typ, Constructor: "scope", Location: "request"}
+}
+
+// instance returns value created by RequestScoped constructor, constructor is invoked once per RequestScope
+func (s *RequestScope) instance(b *RequestScoped, resolving []*RequestScoped) (reflect.Value, error) {
+	if v, ok := s.values[b]; ok {
+		return v, nil
+	}
+
+	for _, r := range resolving {
+		if r == b {
+			return reflect.Value{}, fmt.Errorf("scoped dependency cycle detected for constructor %s (%s)", b.constructor.Name, b.constructor.Location)
+		}
+	}
+	resolving = append(resolving, b)
+
+	typ := b.fn.Type()
+	in := make([]reflect.Value, typ.NumIn())
+	for i := range in {
+		t := typ.In(i)
+		if isParamStruct(t) {
+			v, err := s.container.resolveParamStruct(t)
+			if err != nil {
+				err.Constructor = b.constructor.Name
+				err.Location = b.constructor.Location
+				return reflect.Value{}, err
+			}
+			in[i] = v
+			continue
+		}
+
+		v, err := s.resolve(t, resolving)
+		if err != nil {
+			if depErr, ok := err.(*DependencyError); ok {
+				depErr.Constructor = b.constructor.Name
+				depErr.Location = b.constructor.Location
+			}
+			return reflect.Value{}, err
+		}
+		in[i] = v
+	}
+
+	out := b.fn.Call(in)
+	if last := out[len(out)-1]; len(out) > 1 && !last.IsNil() {
+		return reflect.Value{}, fmt.Errorf("constructor %s (%s) failed. Error: %w", b.constructor.Name, b.constructor.Location, last.Interface().(error))
+	}
+	if len(out) == 3 && !out[1].IsNil() {
+		s.cleanups = append(s.cleanups, out[1].Interface().(func()))
+	}
+
+	s.values[b] = out[0]
+	return out[0], nil
+}
+
+// ScopedInjector returns struct injector which binds values created by RequestScoped
+// constructors to the fields of "v" struct value or pointer, using Dynamic bindings.
+// Fields resolvable from container values are not bound.
+//
+// Injector Scope is Stateless when at least one field is bound,
+// see StructInjector.InjectScope.
+func (c *Container) ScopedInjector(v interface{}) *StructInjector {
+	val := ValueOf(v)
+	s := &StructInjector{
+		initRef:        val,
+		initRefAsSlice: []reflect.Value{val},
+		elemType:       IndirectType(val.Type()),
+	}
+
+	for _, f := range lookupFields(s.elemType, true, nil) {
+		if f.Inject != "" || c.valueTypeExists(f.Type) {
+			continue
+		}
+
+		b := c.scopedBinding(f.Type)
+		if b == nil {
+			continue
+		}
+
+		s.fields = append(s.fields, &targetStructField{
+			FieldIndex: f.Index,
+			Object: &BindObject{
+				Type:     b.Type(),
+				BindType: Dynamic,
+				ResolveValue: func(ctx []reflect.Value) (reflect.Value, error) {
+					scope := ctx[0].Interface().(*RequestScope)
+					scope.mu.Lock()
+					defer scope.mu.Unlock()
+					return scope.instance(b, nil)
+				},
+			},
+			scoped: b,
+		})
+	}
+
+	s.Has = len(s.fields) > 0
+	s.CanInject = s.Has
+	if !s.Has {
+		s.Scope = Singleton
+	}
+
+	return s
+}
@@ -0,0 +1,127 @@
+package di
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type scopedUser struct {
+	name string
+}
+
+type scopedTx struct {
+	user   *scopedUser
+	closed bool
+}
+
+type scopedHandler struct {
+	Config *testConfig
+	User   *scopedUser
+	Tx     *scopedTx
+}
+
+type testConfig struct{}
+
+func TestRequestScopeResolve(t *testing.T) {
+	c := NewContainer()
+	c.Add(&testConfig{})
+
+	calls := 0
+	user, err := NewRequestScoped(func(r *http.Request) *scopedUser {
+		calls++
+		return &scopedUser{name: r.Header.Get("X-User")}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(user)
+
+	var txs []*scopedTx
+	tx, err := NewRequestScoped(func(u *scopedUser, cfg *testConfig) (*scopedTx, func(), error) {
+		tx := &scopedTx{user: u}
+		txs = append(txs, tx)
+		return tx, func() { tx.closed = true }, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(tx)
+
+	h := &scopedHandler{Config: &testConfig{}}
+	si := c.ScopedInjector(h)
+	if !si.CanInject || si.Scope != Stateless {
+		t.Fatalf("expected stateless injector, got %+v", si)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-User", "alice")
+	scope := c.NewRequestScope(r)
+
+	v, err := si.InjectScope(scope)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := v.Interface().(*scopedHandler)
+	if got == h || h.User != nil || h.Tx != nil {
+		t.Fatal("registered handler must not be modified")
+	}
+	if got.User.name != "alice" || got.Tx.user != got.User || got.Config != h.Config {
+		t.Errorf("wrong injected values: %+v", got)
+	}
+	if calls != 1 {
+		t.Errorf("request scoped constructor should be invoked once per scope, got %d calls", calls)
+	}
+
+	scope.Close()
+	if !txs[0].closed {
+		t.Error("expected cleanup to be invoked when scope is closed")
+	}
+
+	v, err = si.InjectScope(c.NewRequestScope(r))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Interface().(*scopedHandler).Tx == txs[0] || calls != 2 {
+		t.Error("expected new values for new scope")
+	}
+}
+
+func TestRequestScopeMissingDependency(t *testing.T) {
+	c := NewContainer()
+	user, err := NewRequestScoped(func(cfg *testConfig) *scopedUser { return &scopedUser{} })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(user)
+
+	_, err = c.NewRequestScope().Resolve(reflect.TypeOf(&scopedUser{}))
+	if _, ok := err.(*DependencyError); !ok {
+		t.Fatalf("expected dependency error, got: %v", err)
+	}
+}
+
+func TestScopedInjectorWithoutScopedFields(t *testing.T) {
+	c := NewContainer()
+	si := c.ScopedInjector(&scopedHandler{})
+	if si.CanInject || si.Scope != Singleton {
+		t.Errorf("expected singleton injector, got %+v", si)
+	}
+}
+
+func TestScopedInjectorError(t *testing.T) {
+	c := NewContainer()
+	user, err := NewRequestScoped(func(cfg *testConfig) *scopedUser { return &scopedUser{} })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Add(user)
+
+	si := c.ScopedInjector(&scopedHandler{})
+	h := &scopedHandler{}
+	err = si.Inject(h, reflect.ValueOf(c.NewRequestScope()))
+	if _, ok := err.(*DependencyError); !ok {
+		t.Fatalf("expected dependency error, got: %v", err)
+	}
+}
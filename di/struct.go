@@ -25,6 +25,8 @@ type (
 	targetStructField struct {
 		Object     *BindObject
 		FieldIndex []int
+		// scoped holds RequestScoped constructor of Dynamic scoped bindings
+		scoped *RequestScoped
 	}
 
 	// StructInjector keeps the data that are needed in order to do the binding injection
@@ -58,6 +60,12 @@ func (s *StructInjector) countBindType(typ BindType) (n int) {
 // embedded unexported fields that contain exported fields
 // of the "v" struct value or pointer.
 func MakeStructInjector(v reflect.Value, values ...reflect.Value) *StructInjector {
+	return newContainerOf(values).structInjector(v)
+}
+
+// structInjector returns a new struct injector for "v" struct value or pointer
+// with fields bound to container values.
+func (c *Container) structInjector(v reflect.Value) *StructInjector {
 	s := &StructInjector{
 		initRef:        v,
 		initRefAsSlice: []reflect.Value{v},
@@ -66,18 +74,14 @@ func MakeStructInjector(v reflect.Value, values ...reflect.Value) *StructInjecto
 
 	fields := lookupFields(s.elemType, true, nil)
 	for _, f := range fields {
-		for _, val := range values {
-			// the binded values to the struct's fields.
+		// the binded values to the struct's fields.
+		if val, ok, _ := c.resolveDependency(Dependency{Type: f.Type, Name: f.Inject, Group: f.Group}); ok {
 			b := MakeBindObject(val)
-
-			if b.IsAssignable(f.Type) {
-				// fmt.Printf("bind the object to the field: %s at index: %#v and type: %s\n", f.Name, f.Index, f.Type.String())
-				s.fields = append(s.fields, &targetStructField{
-					FieldIndex: f.Index,
-					Object:     &b,
-				})
-				break
-			}
+			// fmt.Printf("bind the object to the field: %s at index: %#v and type: %s\n", f.Name, f.Index, f.Type.String())
+			s.fields = append(s.fields, &targetStructField{
+				FieldIndex: f.Index,
+				Object:     &b,
+			})
 		}
 	}
 
@@ -162,23 +166,59 @@ func (s *StructInjector) String() (trace string) {
 }
 
 // Inject accepts a destination struct and any optional context value(s),
-func (s *StructInjector) Inject(dest interface{}, ctx ...reflect.Value) {
+// error is returned when value of Dynamic binding can not be resolved
+func (s *StructInjector) Inject(dest interface{}, ctx ...reflect.Value) error {
 	if dest == nil {
-		return
+		return nil
 	}
 
 	v := IndirectValue(ValueOf(dest))
-	s.InjectElem(v, ctx...)
-
+	return s.InjectElem(v, ctx...)
 }
 
 // InjectElem same as `Inject` but accepts a reflect.Value and bind the necessary fields directly.
-func (s *StructInjector) InjectElem(destElem reflect.Value, ctx ...reflect.Value) {
+func (s *StructInjector) InjectElem(destElem reflect.Value, ctx ...reflect.Value) error {
 	for _, f := range s.fields {
-		f.Object.Assign(ctx, func(v reflect.Value) {
+		if err := f.Object.Assign(ctx, func(v reflect.Value) {
 			destElem.FieldByIndex(f.FieldIndex).Set(v)
-		})
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InjectScope returns a shallow copy of the struct used for resolving the dependencies
+// with Dynamic scoped bindings resolved from given RequestScope.
+// The struct itself is returned when the injector scope is Singleton.
+//
+// See `Container.ScopedInjector` for more.
+func (s *StructInjector) InjectScope(scope *RequestScope) (reflect.Value, error) {
+	if s.Scope == Singleton {
+		return s.initRef, nil
+	}
+
+	dest := reflect.New(s.elemType)
+	dest.Elem().Set(IndirectValue(s.initRef))
+
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	for _, f := range s.fields {
+		if f.scoped == nil {
+			continue
+		}
+		v, err := scope.instance(f.scoped, nil)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		dest.Elem().FieldByIndex(f.FieldIndex).Set(v)
+	}
+
+	if s.initRef.Kind() != reflect.Ptr {
+		return dest.Elem(), nil
 	}
+	return dest, nil
 }
 
 // Acquire returns a new value of the struct or
@@ -0,0 +1,116 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/go-flow/flow/v2/di"
+)
+
+// ModuleProvider interface is implemented by providers of configurable modules,
+// which can be imported more than once with different configurations.
+//
+// Module created by the provider is qualified by the provider name,
+// eg. `db.Module[replica]`, and its config is registered to the module container,
+// replacing config of the same type registered by parent modules.
+// Exports of the module are registered to the importing module under the
+// provider name, see NewModuleProvider.
+type ModuleProvider interface {
+	Provider
+	Name() string
+	Config() interface{}
+}
+
+type moduleProvider struct {
+	name        string
+	config      interface{}
+	constructor interface{}
+}
+
+func (mp *moduleProvider) Provide(injector Injector) (interface{}, error) {
+	if ci, ok := injector.(configInjector); ok {
+		return ci.provideWith(mp.constructor, mp.config)
+	}
+	return injector.Provide(mp.constructor)
+}
+
+// Constructor returns constructor function used to create module factory
+func (mp *moduleProvider) Constructor() interface{} {
+	return mp.constructor
+}
+
+func (mp *moduleProvider) Name() string {
+	return mp.name
+}
+
+func (mp *moduleProvider) Config() interface{} {
+	return mp.config
+}
+
+// NewModuleProvider creates provider of configurable module named by given name.
+// Constructor parameters are injected from the importing module container and
+// given config, eg.
+//
+// NewModuleProvider("replica", db.Config{DSN: dsn}, db.NewModule)
+//
+// Exported instances are registered to the importing module under the module name,
+// so they are injected to fields tagged with `inject:"replica"`. Named exports are
+// registered as `replica.name` and group exports are added to their groups.
+func NewModuleProvider(name string, config interface{}, constructor interface{}) ModuleProvider {
+	return &moduleProvider{
+		name:        name,
+		config:      config,
+		constructor: constructor,
+	}
+}
+
+// configInjector is implemented by injectors which can inject additional values
+type configInjector interface {
+	provideWith(constructor interface{}, values ...interface{}) (interface{}, error)
+}
+
+// provideWith invokes constructor with dependencies injected from module container
+// and given values, which replace container values of the same type
+func (m *Module) provideWith(constructor interface{}, values ...interface{}) (interface{}, error) {
+	c := m.container.Clone()
+	if err := replaceValues(c, values...); err != nil {
+		return nil, err
+	}
+
+	val, cleanup, err := c.ProvideWithCleanup(constructor)
+	if err != nil {
+		return nil, fmt.Errorf("%w in module chain %s", err, m.chain())
+	}
+	if cleanup != nil {
+		m.addCleanup(cleanup)
+	}
+	return val, nil
+}
+
+// replaceValues adds values to the container removing values of the same type
+func replaceValues(c *di.Container, values ...interface{}) error {
+	for _, v := range values {
+		if v == nil {
+			return fmt.Errorf("module config can not be nil")
+		}
+		c.Remove(v, c.Len())
+		c.Add(v)
+	}
+	return nil
+}
+
+// registerExport adds instance exported by the module to the container of importing module.
+// Exports of modules created by ModuleProvider are registered under the module name.
+func (m *Module) registerExport(c *di.Container, p Provider, obj interface{}) {
+	_, group := p.(GroupProvider)
+	_, decorator := p.(*decoratorProvider)
+	gp, global := p.(GlobalProvider)
+	if m.qualifier == "" || group || decorator || (global && gp.Global()) {
+		register(c, p, obj)
+		return
+	}
+	if np, ok := p.(NamedProvider); ok {
+		c.AddNamed(m.qualifier+"."+np.Name(), obj)
+		return
+	}
+	c.AddNamed(m.qualifier, obj)
+}
@@ -0,0 +1,83 @@
+package flow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-flow/flow/v2/di"
+)
+
+type dbConfig struct {
+	DSN string
+}
+
+type dbModule struct {
+	testModule
+	config dbConfig
+}
+
+func newDBModule(cfg dbConfig) *dbModule {
+	return &dbModule{
+		testModule: testModule{
+			exports: []Provider{
+				NewProvider(func(cfg dbConfig) *testDB { return &testDB{dsn: cfg.DSN} }),
+				NewNamedProvider("config", func(cfg dbConfig) *dbConfig { return &cfg }),
+			},
+		},
+		config: cfg,
+	}
+}
+
+type dbParams struct {
+	di.In
+	Primary *testDB   `inject:"primary"`
+	Replica *testDB   `inject:"replica"`
+	Config  *dbConfig `inject:"replica.config"`
+}
+
+func TestModuleProvider(t *testing.T) {
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewValueProvider(dbConfig{DSN: "root"}),
+		},
+		modules: []Provider{
+			NewModuleProvider("primary", dbConfig{DSN: "primary"}, newDBModule),
+			NewModuleProvider("replica", dbConfig{DSN: "replica"}, newDBModule),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var p dbParams
+	if err := m.Invoke(func(params dbParams) { p = params }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Primary.dsn != "primary" || p.Replica.dsn != "replica" || p.Config.DSN != "replica" {
+		t.Errorf("wrong exported instances: %s, %s, %s", p.Primary.dsn, p.Replica.dsn, p.Config.DSN)
+	}
+
+	// exports of configurable modules are not registered by type
+	if err := m.Invoke(func(*testDB) {}); err == nil {
+		t.Error("expected missing dependency error")
+	}
+
+	g := m.Graph()
+	if len(g.Modules) != 3 || g.Modules[1].Name != "flow.dbModule[primary]" || g.Modules[2].Name != "flow.dbModule[replica]" {
+		t.Errorf("wrong modules: %+v", g.Modules)
+	}
+	if m.modules[0].factory.(*dbModule).config.DSN != "primary" {
+		t.Errorf("config is not injected to module constructor")
+	}
+}
+
+func TestModuleProviderNilConfig(t *testing.T) {
+	_, err := Bootstrap(&testModule{
+		modules: []Provider{
+			NewModuleProvider("primary", nil, newDBModule),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "module config can not be nil") {
+		t.Errorf("expected nil config error, got: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package flow
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/go-flow/flow/v2/di"
@@ -62,19 +63,30 @@ type RouterFactory interface {
 }
 
 // ModuleStarter interface used when http application is served
-// Start method is invoked if module implements the interface
+// Start method is invoked if module implements the interface,
+// imported modules are started before modules importing them
 type ModuleStarter interface {
 	Start() error
 }
 
 // ModuleStopper interface used when http application is stopped
-// Stop method is invoked during shutdown process if module implements the interface
+// Stop method is invoked during shutdown process if module implements the interface,
+// modules are stopped in reverse order of their start
 type ModuleStopper interface {
 	Stop()
 }
 
+// ModuleGracefulStopper interface used when http application is stopped
+// Stop method is invoked during shutdown process, after open connections are drained,
+// with context holding the deadline of Options.StopTimeout,
+// returned error is reported by Module.Stop
+type ModuleGracefulStopper interface {
+	Stop(ctx context.Context) error
+}
+
 // Bootstrap creates Flow Module instance for given factory object
-func Bootstrap(moduleFactory ModuleFactory) (*Module, error) {
-	rootModule, err := NewModule(moduleFactory, di.NewContainer(), nil)
+// configured with given bootstrap options, eg. provider overrides used in tests.
+func Bootstrap(moduleFactory ModuleFactory, options ...BootstrapOption) (*Module, error) {
+	rootModule, err := NewModule(moduleFactory, di.NewContainer(), nil, options...)
 	return rootModule, err
 }
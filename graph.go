@@ -0,0 +1,487 @@
+package flow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/go-flow/flow/v2/di"
+)
+
+// ConstructorProvider interface is implemented by providers
+// which create instances using constructor function.
+//
+// Constructors of such providers are inspected in the whole module tree before any
+// of them is invoked, so providers are instantiated in dependency order and dependency
+// cycles or unsatisfiable dependencies are reported before instantiation.
+// Instances injected to module factory constructors are created when the factory is created.
+type ConstructorProvider interface {
+	Provider
+	Constructor() interface{}
+}
+
+// Provider kinds used in dependency graph
+const (
+	ProviderKindImport = "import"
+	ProviderKindExport = "export"
+)
+
+// Provider scopes of providers which create instances on demand used in dependency graph
+const (
+	ProviderScopeRequest = "request"
+	ProviderScopeLazy    = "lazy"
+	ProviderScopeFactory = "factory"
+)
+
+// Graph is dependency graph of bootstrapped modules
+type Graph struct {
+	Modules []*GraphModule `json:"modules"`
+}
+
+// GraphModule describes module in dependency graph
+type GraphModule struct {
+	Name      string           `json:"name"`
+	Parent    string           `json:"parent,omitempty"`
+	Imports   []string         `json:"imports,omitempty"`
+	Providers []*GraphProvider `json:"providers,omitempty"`
+	Routers   []*GraphRouter   `json:"routers,omitempty"`
+}
+
+// GraphProvider describes provider in dependency graph
+type GraphProvider struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"`
+	Type        string `json:"type"`
+	Name        string `json:"name,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+	Constructor string `json:"constructor,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Overridden  bool   `json:"overridden,omitempty"`
+	Decorator   bool   `json:"decorator,omitempty"`
+	Global      bool   `json:"global,omitempty"`
+
+	Dependencies []*GraphDependency `json:"dependencies,omitempty"`
+}
+
+// GraphDependency describes provider dependency in dependency graph
+type GraphDependency struct {
+	Type  string `json:"type"`
+	Name  string `json:"name,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+// GraphRouter describes module router in dependency graph
+type GraphRouter struct {
+	Type     string          `json:"type"`
+	Path     string          `json:"path"`
+	Handlers []*GraphHandler `json:"handlers,omitempty"`
+}
+
+// GraphHandler describes action handler in dependency graph
+type GraphHandler struct {
+	Type   string `json:"type"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// Graph returns dependency graph of the module and all imported modules
+func (m *Module) Graph() *Graph {
+	g := &Graph{}
+	m.collectGraph(g)
+	return g
+}
+
+func (m *Module) collectGraph(g *Graph) {
+	gm := &GraphModule{
+		Name:      m.name,
+		Providers: m.graph.providers,
+		Routers:   m.graph.routers,
+	}
+	if m.parent != nil {
+		gm.Parent = m.parent.name
+	}
+	for _, child := range m.modules {
+		gm.Imports = append(gm.Imports, child.name)
+	}
+
+	g.Modules = append(g.Modules, gm)
+	for _, child := range m.modules {
+		// shared modules are described by the module which created them
+		if child.parent == m {
+			child.collectGraph(g)
+		}
+	}
+}
+
+// JSON returns graph encoded as indented JSON document
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// DOT returns graph in Graphviz DOT format
+func (g *Graph) DOT() string {
+	var b bytes.Buffer
+	g.WriteDOT(&b)
+	return b.String()
+}
+
+// WriteDOT writes graph in Graphviz DOT format to given writer.
+//
+// Each module is rendered as a cluster holding its providers, routers and handlers.
+// Edges point from dependency to the provider which depends on it
+// and from module to the modules it imports.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b bytes.Buffer
+
+	b.WriteString("digraph flow {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+
+	producers := map[string]string{}
+	for _, gm := range g.Modules {
+		for _, p := range gm.Providers {
+			key := p.Type + "|" + p.Name
+			if _, ok := producers[key]; !ok {
+				producers[key] = p.ID
+			}
+		}
+	}
+
+	for i, gm := range g.Modules {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(gm.Name))
+		fmt.Fprintf(&b, "    %s [label=%s, shape=folder];\n", dotQuote("module:"+gm.Name), dotQuote(gm.Name))
+		for _, p := range gm.Providers {
+			label := p.Type
+			if p.Name != "" {
+				label += "\\n`" + p.Name + "`"
+			}
+			label += "\\n(" + p.Kind + ")"
+			fmt.Fprintf(&b, "    %s [label=%s];\n", dotQuote(p.ID), dotQuote(label))
+		}
+		for j, r := range gm.Routers {
+			rid := fmt.Sprintf("router:%s:%d", gm.Name, j)
+			fmt.Fprintf(&b, "    %s [label=%s, shape=component];\n", dotQuote(rid), dotQuote(r.Type+"\\n"+r.Path))
+			for k, h := range r.Handlers {
+				hid := fmt.Sprintf("%s:%d", rid, k)
+				fmt.Fprintf(&b, "    %s [label=%s, shape=ellipse];\n", dotQuote(hid), dotQuote(h.Method+" "+h.Path+"\\n"+h.Type))
+				fmt.Fprintf(&b, "    %s -> %s;\n", dotQuote(rid), dotQuote(hid))
+			}
+		}
+		b.WriteString("  }\n")
+	}
+
+	for _, gm := range g.Modules {
+		for _, imp := range gm.Imports {
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed];\n", dotQuote("module:"+gm.Name), dotQuote("module:"+imp))
+		}
+		for _, p := range gm.Providers {
+			for _, dep := range p.Dependencies {
+				if id, ok := producers[dep.Type+"|"+dep.Name]; ok {
+					fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(id), dotQuote(p.ID))
+				}
+			}
+		}
+	}
+
+	b.WriteString("}\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// moduleGraph holds graph information recorded during module bootstrap
+type moduleGraph struct {
+	providers []*GraphProvider
+	routers   []*GraphRouter
+}
+
+// planNode is a provider of single batch planned for instantiation
+type planNode struct {
+	provider    Provider
+	name        string
+	group       string
+	scope       string
+	overridden  bool
+	decorator   bool
+	global      bool
+	constructor *di.Constructor
+	// deps holds indexes of batch providers current provider depends on
+	deps []int
+}
+
+// provides returns true if instance created by provider can be resolved as given dependency
+func (n *planNode) provides(d di.Dependency) bool {
+	return n.constructor != nil && n.scope != ProviderScopeRequest && !n.decorator && n.constructor.Provides(d, n.name, n.group)
+}
+
+// dynamic returns true if provider creates instance of an interface type, which is registered
+// by its dynamic type, so it may provide implementations of the interface, see Module.provide
+func (n *planNode) dynamic() bool {
+	return n.constructor != nil && n.scope == "" && !n.decorator && n.constructor.Result.Kind() == reflect.Interface
+}
+
+// mayProvide returns true if instance created by provider of an interface type
+// may be resolved as given dependency by its dynamic type
+func (n *planNode) mayProvide(d di.Dependency) bool {
+	if !n.dynamic() {
+		return false
+	}
+	typ := d.Type
+	if d.Multiple() {
+		if d.Group != n.group || n.name != "" {
+			return false
+		}
+		typ = typ.Elem()
+	} else if d.Name != n.name || n.group != "" {
+		return false
+	}
+	return typ != n.constructor.Result && typ.Implements(n.constructor.Result)
+}
+
+func (n *planNode) String() string {
+	if n.constructor == nil {
+		return reflect.TypeOf(n.provider).String()
+	}
+	if n.name != "" {
+		return fmt.Sprintf("%s `%s` (%s)", n.constructor.Result, n.name, n.constructor.Name)
+	}
+	return fmt.Sprintf("%s (%s)", n.constructor.Result, n.constructor.Name)
+}
+
+// plan inspects constructors of providers and returns providers sorted in dependency order.
+//
+// Dependencies resolvable from module container are resolved from it, so the first
+// registered value wins as it does when providers are instantiated in declared order.
+// Remaining dependencies are resolved from the first provider of the batch creating them.
+// Providers which do not expose constructor are kept in declared order.
+func (m *Module) plan(kind string, providers []Provider) ([]*planNode, error) {
+	nodes := make([]*planNode, len(providers))
+	opaque := false
+
+	for i, p := range providers {
+		n := &planNode{provider: p}
+		if np, ok := p.(NamedProvider); ok {
+			n.name = np.Name()
+		}
+		if gp, ok := p.(GroupProvider); ok {
+			n.group = gp.Group()
+		}
+		c, err := inspectProvider(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w in module chain %s", err, m.chain())
+		}
+		if c == nil {
+			opaque = true
+			nodes[i] = n
+			continue
+		}
+		if _, decorator := p.(*decoratorProvider); n.group == "" && !decorator {
+			op, err := m.overrideProvider(p, c, n.name)
+			if err != nil {
+				return nil, fmt.Errorf("%w in module chain %s", err, m.chain())
+			}
+			if op != nil {
+				// override constructors are validated before bootstrap
+				c, _ = inspectProvider(op)
+				n.provider = op
+				n.overridden = true
+			}
+		}
+		n.constructor = c
+		if sp, ok := n.provider.(scopedProvider); ok {
+			n.scope = sp.scope()
+		}
+		if _, ok := n.provider.(*decoratorProvider); ok {
+			n.decorator = true
+		}
+		if gp, ok := n.provider.(GlobalProvider); ok {
+			n.global = gp.Global()
+		}
+		nodes[i] = n
+	}
+
+	for i, n := range nodes {
+		// request scoped dependencies are resolved when request is handled
+		if n.constructor == nil || n.scope == ProviderScopeRequest {
+			continue
+		}
+	deps:
+		for k, d := range n.constructor.Dependencies {
+			// instances are decorated by decorators of the batch before they are injected,
+			// decorators receive the decorated instance undecorated as the first parameter
+			if !n.decorator || k > 0 {
+				for j, dn := range nodes {
+					if j != i && dn.decorator && dn.constructor.Result == d.Type && d.Name == "" && d.Group == "" {
+						n.deps = append(n.deps, j)
+					}
+				}
+			}
+
+			// multiple dependencies are resolved after all providers of the batch creating them
+			if d.Multiple() {
+				for j, dn := range nodes {
+					if j != i && (dn.provides(d) || dn.mayProvide(d)) {
+						n.deps = append(n.deps, j)
+					}
+				}
+				continue
+			}
+			if m.container.CanResolve(d) {
+				continue
+			}
+			for j, dn := range nodes {
+				if j != i && dn.provides(d) {
+					n.deps = append(n.deps, j)
+					continue deps
+				}
+			}
+			// instances of interface types are resolved by their dynamic type when created
+			for j, dn := range nodes {
+				if j != i && dn.mayProvide(d) {
+					n.deps = append(n.deps, j)
+					continue deps
+				}
+			}
+			// instances of opaque providers are known only after instantiation,
+			// lazy and factory dependencies are resolved when instance is injected
+			if opaque || d.Optional || n.scope != "" {
+				continue
+			}
+			depErr := &di.DependencyError{
+				Type:        d.Type,
+				Name:        d.Name,
+				Field:       d.Field,
+				Constructor: n.constructor.Name,
+				Location:    n.constructor.Location,
+				Available:   m.container.Names(d.Type),
+			}
+			if err := m.visibilityError(depErr); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w in module chain %s", depErr, m.chain())
+		}
+	}
+
+	// depth first topological sort keeping declared order of independent providers
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(nodes))
+	sorted := make([]*planNode, 0, len(nodes))
+	var stack []int
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return m.cycleError(nodes, append(stack, i))
+		}
+
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, d := range nodes[i].deps {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+		sorted = append(sorted, nodes[i])
+		return nil
+	}
+
+	for i := range nodes {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, n := range sorted {
+		m.graph.providers = append(m.graph.providers, m.graphProvider(kind, n))
+	}
+	m.provided = append(m.provided, sorted...)
+
+	return sorted, nil
+}
+
+// scopedProvider is implemented by providers which create instances on demand
+type scopedProvider interface {
+	ConstructorProvider
+	scope() string
+}
+
+// inspector is implemented by providers which describe provided instance
+// without constructor function, eg. value and alias providers
+type inspector interface {
+	inspect() (*di.Constructor, error)
+}
+
+// inspectProvider returns information about instance created by provider
+// or nil when provider does not describe it
+func inspectProvider(p Provider) (*di.Constructor, error) {
+	switch ip := p.(type) {
+	case inspector:
+		return ip.inspect()
+	case ConstructorProvider:
+		return di.Inspect(ip.Constructor())
+	}
+	return nil, nil
+}
+
+// cycleError creates error describing dependency cycle found on the stack
+func (m *Module) cycleError(nodes []*planNode, stack []int) error {
+	last := stack[len(stack)-1]
+	start := 0
+	for i, idx := range stack {
+		if idx == last {
+			start = i
+			break
+		}
+	}
+
+	path := make([]string, 0, len(stack)-start)
+	for _, idx := range stack[start:] {
+		path = append(path, nodes[idx].String())
+	}
+	return fmt.Errorf("dependency cycle detected in module chain %s: %s", m.chain(), strings.Join(path, " -> "))
+}
+
+func (m *Module) graphProvider(kind string, n *planNode) *GraphProvider {
+	gp := &GraphProvider{
+		ID:    fmt.Sprintf("%s:%s:%d", m.name, kind, len(m.graph.providers)),
+		Kind:  kind,
+		Name:  n.name,
+		Group: n.group,
+		Scope: n.scope,
+
+		Overridden: n.overridden,
+		Decorator:  n.decorator,
+		Global:     n.global,
+	}
+
+	if n.constructor == nil {
+		gp.Type = reflect.TypeOf(n.provider).String()
+		return gp
+	}
+
+	gp.Type = n.constructor.Result.String()
+	gp.Constructor = n.constructor.Name
+	gp.Location = n.constructor.Location
+	for _, d := range n.constructor.Dependencies {
+		gp.Dependencies = append(gp.Dependencies, &GraphDependency{Type: d.Type.String(), Name: d.Name, Group: d.Group})
+	}
+	return gp
+}
@@ -0,0 +1,325 @@
+package flow
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-flow/flow/v2/di"
+)
+
+type testCache struct {
+	repo *testRepo
+}
+
+type testService struct {
+	cache *testCache
+}
+
+func TestModuleProvidersDependencyOrder(t *testing.T) {
+	var svc *testService
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func(c *testCache) *testService {
+				svc = &testService{cache: c}
+				return svc
+			}),
+			NewProvider(func(r *testRepo) *testCache { return &testCache{repo: r} }),
+			NewProvider(func(db *testDB) *testRepo { return &testRepo{db: db} }),
+			NewProvider(func() *testDB { return &testDB{dsn: "db"} }),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if svc == nil || svc.cache.repo.db.dsn != "db" {
+		t.Errorf("dependencies are not resolved: %+v", svc)
+	}
+}
+
+func TestModuleDependencyCycle(t *testing.T) {
+	invoked := false
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB {
+				invoked = true
+				return &testDB{}
+			}),
+			NewProvider(func(c *testCache) *testRepo { return &testRepo{} }),
+			NewProvider(func(s *testService) *testCache { return &testCache{} }),
+			NewProvider(func(r *testRepo) *testService { return &testService{} }),
+		},
+	})
+	if err == nil {
+		t.Fatal("expected dependency cycle error")
+	}
+	if invoked {
+		t.Error("constructors should not be invoked when dependency cycle exists")
+	}
+
+	msg := err.Error()
+	want := "*flow.testRepo (github.com/go-flow/flow/v2.TestModuleDependencyCycle.func2) -> " +
+		"*flow.testCache (github.com/go-flow/flow/v2.TestModuleDependencyCycle.func3) -> " +
+		"*flow.testService (github.com/go-flow/flow/v2.TestModuleDependencyCycle.func4) -> " +
+		"*flow.testRepo (github.com/go-flow/flow/v2.TestModuleDependencyCycle.func2)"
+	if !strings.Contains(msg, want) {
+		t.Errorf("error message %q does not contain cycle path %q", msg, want)
+	}
+}
+
+func TestModuleUnsatisfiableDependency(t *testing.T) {
+	invoked := false
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB {
+				invoked = true
+				return &testDB{}
+			}),
+			NewProvider(func(c *testConfig) *testRepo { return &testRepo{} }),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing dependency `*flow.testConfig`") {
+		t.Fatalf("expected missing dependency error, got: %v", err)
+	}
+	if invoked {
+		t.Error("constructors should not be invoked when dependency can not be satisfied")
+	}
+}
+
+func TestModuleTreeUnsatisfiableDependency(t *testing.T) {
+	invoked := false
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB {
+				invoked = true
+				return &testDB{}
+			}),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func(c *testConfig) *testRepo { return &testRepo{} }),
+					},
+				}}
+			}),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing dependency `*flow.testConfig`") {
+		t.Fatalf("expected missing dependency error, got: %v", err)
+	}
+	if invoked {
+		t.Error("constructors should not be invoked when dependency of any module can not be satisfied")
+	}
+}
+
+type testStore interface {
+	DSN() string
+}
+
+type testSQLStore struct {
+	db *testDB
+}
+
+func (s *testSQLStore) DSN() string { return s.db.dsn }
+
+func TestModuleInterfaceProviderDynamicType(t *testing.T) {
+	var store *testSQLStore
+	var child *testSQLStore
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func(s *testSQLStore) *testRepo {
+				store = s
+				return &testRepo{}
+			}),
+			NewProvider(func(db *testDB) testStore { return &testSQLStore{db: db} }),
+			NewProvider(func() *testDB { return &testDB{dsn: "db"} }),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func(s *testSQLStore) *testCache {
+							child = s
+							return &testCache{}
+						}),
+					},
+				}}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if store == nil || store.DSN() != "db" {
+		t.Errorf("instance of interface type should be resolved by its dynamic type, got: %+v", store)
+	}
+	if child != store {
+		t.Errorf("imported module should resolve the same instance, got: %p, expected: %p", child, store)
+	}
+}
+
+type graphRouter struct{}
+
+func (graphRouter) Path() string                         { return "/users" }
+func (graphRouter) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (graphRouter) RegisterSubRouters() bool             { return false }
+func (graphRouter) ProvideHandlers() []Provider {
+	return []Provider{NewProvider(func(r *testRepo) *graphHandler { return &graphHandler{} })}
+}
+
+type graphHandler struct{}
+
+func (graphHandler) Method() string                       { return http.MethodGet }
+func (graphHandler) Path() string                         { return "/:id" }
+func (graphHandler) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (graphHandler) Handle(r *http.Request) Response      { return ResponseNoContent() }
+
+func TestModuleGraph(t *testing.T) {
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB { return &testDB{} }),
+			NewNamedProvider("replica", func() *testDB { return &testDB{} }),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					exports: []Provider{
+						NewProvider(func(db *testDB) *testRepo { return &testRepo{db: db} }),
+					},
+				}}
+			}),
+		},
+		routers: []Provider{
+			NewProvider(func() *graphRouter { return &graphRouter{} }),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g := m.Graph()
+	if len(g.Modules) != 2 {
+		t.Fatalf("wrong number of modules: %d", len(g.Modules))
+	}
+
+	root, child := g.Modules[0], g.Modules[1]
+	if root.Name != "flow.testModule" || child.Name != "flow.childModule" || child.Parent != root.Name {
+		t.Errorf("wrong modules: %+v, %+v", root, child)
+	}
+	if len(root.Imports) != 1 || root.Imports[0] != child.Name {
+		t.Errorf("wrong module imports: %v", root.Imports)
+	}
+	if len(root.Providers) != 2 || root.Providers[1].Name != "replica" || root.Providers[1].Kind != ProviderKindImport {
+		t.Errorf("wrong root providers: %+v", root.Providers)
+	}
+	if len(child.Providers) != 1 || child.Providers[0].Kind != ProviderKindExport || child.Providers[0].Type != "*flow.testRepo" {
+		t.Errorf("wrong child providers: %+v", child.Providers)
+	}
+	if len(root.Routers) != 1 || len(root.Routers[0].Handlers) != 1 || root.Routers[0].Handlers[0].Path != "/users/:id" {
+		t.Errorf("wrong routers: %+v", root.Routers)
+	}
+
+	data, err := g.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Graph
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid JSON graph: %v", err)
+	}
+	if len(decoded.Modules) != 2 {
+		t.Errorf("wrong number of decoded modules: %d", len(decoded.Modules))
+	}
+
+	dot := g.DOT()
+	for _, want := range []string{
+		"digraph flow {",
+		`label="flow.childModule";`,
+		`"module:flow.testModule" -> "module:flow.childModule" [style=dashed];`,
+		`"flow.testModule:import:0" -> "flow.childModule:export:0";`,
+		`GET /users/:id`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output does not contain %q:\n%s", want, dot)
+		}
+	}
+}
+
+type healthChecker interface {
+	Check() string
+}
+
+type dbCheck struct{ name string }
+
+func (c *dbCheck) Check() string { return c.name }
+
+type healthParams struct {
+	di.In
+	Checkers []healthChecker `group:"health"`
+}
+
+type healthService struct {
+	checks []string
+}
+
+func TestModuleGroupProviders(t *testing.T) {
+	var svc *healthService
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func(p healthParams) *healthService {
+				svc = &healthService{}
+				for _, c := range p.Checkers {
+					svc.checks = append(svc.checks, c.Check())
+				}
+				return svc
+			}),
+			NewGroupProvider("health", func() *dbCheck { return &dbCheck{name: "primary"} }),
+			NewGroupProvider("health", func() *dbCheck { return &dbCheck{name: "replica"} }),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					exports: []Provider{
+						NewGroupProvider("health", func() *dbCheck { return &dbCheck{name: "cache"} }),
+					},
+				}}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"primary", "replica"}; !reflect.DeepEqual(svc.checks, want) {
+		t.Errorf("wrong group values: want %v, got %v", want, svc.checks)
+	}
+
+	var checks []string
+	err = m.Invoke(func(p healthParams) {
+		for _, c := range p.Checkers {
+			checks = append(checks, c.Check())
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"primary", "replica", "cache"}; !reflect.DeepEqual(checks, want) {
+		t.Errorf("exported group values are not collected: want %v, got %v", want, checks)
+	}
+
+	providers := m.Graph().Modules[0].Providers
+	if providers[0].Group != "health" || providers[2].Dependencies[0].Group != "health" {
+		t.Errorf("wrong group providers in graph: %+v, %+v", providers[0], providers[2])
+	}
+}
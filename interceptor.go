@@ -0,0 +1,81 @@
+package flow
+
+import (
+	"net/http"
+
+	"github.com/go-flow/flow/v2/response"
+)
+
+// Interceptor is a function invoked after action handler returns the Response
+// and before the Response is written. Interceptor can inspect the Response
+// and return it unchanged, transform it or replace it with another Response.
+//
+// func Envelope(r *http.Request, res Response) Response {
+// 	data, ok := PayloadOf(res)
+// 	if !ok {
+// 		return res
+// 	}
+// 	if wrapped, ok := ReplacePayload(res, Map{"data": data}); ok {
+// 		return wrapped
+// 	}
+// 	return res
+// }
+//
+// Response passed to interceptor is nil when action handler returns nil.
+type Interceptor func(r *http.Request, res Response) Response
+
+// ModuleInterceptor interface is used for providing interceptors
+// applied to all action handlers registered by module routers
+type ModuleInterceptor interface {
+	Interceptors() []Interceptor
+}
+
+// RouterInterceptor interface is used for providing interceptors
+// applied to all action handlers registered by router
+type RouterInterceptor interface {
+	Interceptors() []Interceptor
+}
+
+// HandlerInterceptor interface is used for providing interceptors
+// applied to action handler
+type HandlerInterceptor interface {
+	Interceptors() []Interceptor
+}
+
+// PayloadOf returns data rendered by given Response.
+// It returns false for Responses that do not expose rendered data.
+func PayloadOf(res Response) (interface{}, bool) {
+	if res == nil {
+		return nil, false
+	}
+	return response.PayloadOf(res)
+}
+
+// ReplacePayload returns copy of given Response which renders given data
+// with the same renderer, status code, headers and cookies.
+// It returns false, together with unchanged Response,
+// when payload of the Response can not be replaced.
+func ReplacePayload(res Response, data interface{}) (Response, bool) {
+	if res == nil {
+		return nil, false
+	}
+	return response.ReplacePayload(res, data)
+}
+
+// intercept wraps handler with interceptors.
+// Interceptors are invoked in reverse order, so the first interceptor
+// is the last one to see the Response, the same way first middleware
+// is the outermost one.
+func intercept(handler HandlerFunc, interceptors []Interceptor) HandlerFunc {
+	if len(interceptors) == 0 {
+		return handler
+	}
+
+	return func(r *http.Request) Response {
+		res := handler(r)
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			res = interceptors[i](r, res)
+		}
+		return res
+	}
+}
@@ -0,0 +1,95 @@
+package flow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func envelope(key string) Interceptor {
+	return func(r *http.Request, res Response) Response {
+		data, ok := PayloadOf(res)
+		if !ok {
+			return res
+		}
+		wrapped, ok := ReplacePayload(res, Map{key: data})
+		if !ok {
+			return res
+		}
+		return wrapped
+	}
+}
+
+func TestRouterIntercept(t *testing.T) {
+	router := NewRouter()
+	router.Intercept(envelope("outer"))
+
+	api := router.Group("/api")
+	api.Intercept(envelope("inner"))
+	api.GET("/user", func(r *http.Request) Response {
+		return WithHeaders(ResponseJSON(http.StatusOK, "gopher"), http.Header{"X-Test": {"1"}})
+	})
+	api.GET("/file", func(r *http.Request) Response {
+		return ResponseRedirect(http.StatusFound, "/")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/user", nil))
+
+	if got, want := w.Body.String(), `{"outer":{"inner":"gopher"}}`; got != want {
+		t.Errorf("wrong body: want %s, got %s", want, got)
+	}
+	if got := w.Header().Get("X-Test"); got != "1" {
+		t.Errorf("decorated header is lost: got %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/file", nil))
+	if w.Code != http.StatusFound {
+		t.Errorf("wrong status code for response without payload: want %d, got %d", http.StatusFound, w.Code)
+	}
+}
+
+type interceptedModule struct{}
+
+func (interceptedModule) ProvideImports() []Provider { return nil }
+func (interceptedModule) ProvideExports() []Provider { return nil }
+func (interceptedModule) ProvideModules() []Provider { return nil }
+func (interceptedModule) ProvideRouters() []Provider {
+	return []Provider{NewProvider(func() *interceptedRouter { return &interceptedRouter{} })}
+}
+func (interceptedModule) Interceptors() []Interceptor { return []Interceptor{envelope("module")} }
+
+type interceptedRouter struct{}
+
+func (interceptedRouter) Path() string                         { return "/" }
+func (interceptedRouter) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (interceptedRouter) RegisterSubRouters() bool             { return false }
+func (interceptedRouter) ProvideHandlers() []Provider {
+	return []Provider{NewProvider(func() *interceptedHandler { return &interceptedHandler{} })}
+}
+func (interceptedRouter) Interceptors() []Interceptor { return []Interceptor{envelope("router")} }
+
+type interceptedHandler struct{}
+
+func (interceptedHandler) Method() string                       { return http.MethodGet }
+func (interceptedHandler) Path() string                         { return "/" }
+func (interceptedHandler) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (interceptedHandler) Handle(r *http.Request) Response {
+	return ResponseJSON(http.StatusOK, 1)
+}
+func (interceptedHandler) Interceptors() []Interceptor { return []Interceptor{envelope("handler")} }
+
+func TestModuleInterceptors(t *testing.T) {
+	m, err := Bootstrap(&interceptedModule{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	m.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got, want := w.Body.String(), `{"module":{"router":{"handler":1}}}`; got != want {
+		t.Errorf("wrong body: want %s, got %s", want, got)
+	}
+}
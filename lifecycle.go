@@ -0,0 +1,249 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Initializer interface is implemented by provided instances and module factories
+// which have to be initialized before application starts serving requests.
+//
+// OnInit methods are invoked by Module.Init in dependency order,
+// dependencies are initialized before instances which depend on them.
+type Initializer interface {
+	OnInit(ctx context.Context) error
+}
+
+// Shutdowner interface is implemented by provided instances and module factories
+// which have to release resources when application is stopped.
+//
+// OnShutdown methods are invoked by Module.Shutdown in reverse dependency order.
+type Shutdowner interface {
+	OnShutdown(ctx context.Context) error
+}
+
+// LifecycleError holds errors returned by lifecycle hooks
+type LifecycleError struct {
+	Errors []error
+}
+
+func (e *LifecycleError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of hook errors matches target
+func (e *LifecycleError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first hook error that matches target
+func (e *LifecycleError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// addHooks registers lifecycle hooks of given object to the root module.
+// Hooks of the instance registered more than once, eg. re-exported by NewExistingProvider,
+// are registered once.
+func (m *Module) addHooks(obj interface{}) {
+	_, init := obj.(Initializer)
+	_, shutdown := obj.(Shutdowner)
+	if !init && !shutdown {
+		return
+	}
+
+	root := m.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if reflect.TypeOf(obj).Comparable() {
+		for _, h := range root.hooks {
+			if h == obj {
+				return
+			}
+		}
+	}
+	root.hooks = append(root.hooks, obj)
+}
+
+// lifecycleHooks returns snapshot of registered lifecycle hooks
+func (m *Module) lifecycleHooks() []interface{} {
+	root := m.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	return root.hooks[:len(root.hooks):len(root.hooks)]
+}
+
+// Init invokes OnInit hooks of provided instances and modules in dependency order.
+// Each hook is invoked with context limited by Options.HookTimeout.
+//
+// When a hook fails, OnShutdown hooks of already initialized instances are invoked
+// in reverse order and all errors are returned as LifecycleError.
+func (m *Module) Init(ctx context.Context) error {
+	root := m.root()
+	hooks := m.lifecycleHooks()
+
+	for i, obj := range hooks {
+		h, ok := obj.(Initializer)
+		if !ok {
+			continue
+		}
+
+		if err := root.runHook(ctx, obj, "OnInit", h.OnInit); err != nil {
+			errs := []error{err}
+			if serr := root.shutdownHooks(ctx, hooks[:i]); serr != nil {
+				errs = append(errs, serr.Errors...)
+			}
+			return &LifecycleError{Errors: errs}
+		}
+	}
+	return nil
+}
+
+// Shutdown invokes OnShutdown hooks of provided instances and modules in reverse dependency order.
+// Each hook is invoked with context limited by Options.HookTimeout.
+//
+// All hooks are invoked even if some of them fail, errors are returned as LifecycleError.
+func (m *Module) Shutdown(ctx context.Context) error {
+	root := m.root()
+	if err := root.shutdownHooks(ctx, m.lifecycleHooks()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Module) shutdownHooks(ctx context.Context, hooks []interface{}) *LifecycleError {
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h, ok := hooks[i].(Shutdowner)
+		if !ok {
+			continue
+		}
+		if err := m.runHook(ctx, hooks[i], "OnShutdown", h.OnShutdown); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &LifecycleError{Errors: errs}
+}
+
+// Start invokes Start method of the module and all imported modules implementing ModuleStarter.
+// Imported modules are started before modules importing them and shared modules are started once.
+//
+// When a module fails to start, already started modules are stopped in reverse order.
+func (m *Module) Start() error {
+	root := m.root()
+	for _, module := range m.importOrder() {
+		if s, ok := module.factory.(ModuleStarter); ok {
+			if err := s.Start(); err != nil {
+				err = fmt.Errorf("unable to start module `%s`. Error: %w", module.name, err)
+				if serr := m.Stop(context.Background()); serr != nil {
+					err = fmt.Errorf("%w; %v", err, serr)
+				}
+				return err
+			}
+		}
+
+		root.mu.Lock()
+		root.started = append(root.started, module)
+		root.mu.Unlock()
+	}
+	return nil
+}
+
+// importOrder returns the module and all imported modules,
+// imported modules precede modules importing them and shared modules are returned once
+func (m *Module) importOrder() []*Module {
+	var modules []*Module
+	seen := map[*Module]bool{}
+
+	var walk func(module *Module)
+	walk = func(module *Module) {
+		if seen[module] {
+			return
+		}
+		seen[module] = true
+		for _, child := range module.modules {
+			walk(child)
+		}
+		modules = append(modules, module)
+	}
+	walk(m)
+	return modules
+}
+
+// Stop invokes Stop method of started modules implementing ModuleStopper
+// or ModuleGracefulStopper in reverse order of their start.
+//
+// All modules are stopped even if some of them fail, errors are returned as LifecycleError.
+func (m *Module) Stop(ctx context.Context) error {
+	root := m.root()
+	root.mu.Lock()
+	started := root.started
+	root.started = nil
+	root.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		switch s := started[i].factory.(type) {
+		case ModuleGracefulStopper:
+			if err := s.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("unable to stop module `%s`. Error: %w", started[i].name, err))
+			}
+		case ModuleStopper:
+			s.Stop()
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &LifecycleError{Errors: errs}
+}
+
+// runHook invokes hook with context limited by hook timeout.
+// Hook which does not return before timeout is abandoned and timeout error is returned.
+func (m *Module) runHook(ctx context.Context, obj interface{}, name string, hook func(context.Context) error) error {
+	if timeout := m.options.HookTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s hook of `%s` failed. Error: %w", name, reflect.TypeOf(obj), err)
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type hookRecorder struct {
+	calls []string
+}
+
+type hooked struct {
+	name    string
+	rec     *hookRecorder
+	initErr error
+	delay   time.Duration
+}
+
+func (h *hooked) OnInit(ctx context.Context) error {
+	if h.delay > 0 {
+		select {
+		case <-time.After(h.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	h.rec.calls = append(h.rec.calls, "init "+h.name)
+	return h.initErr
+}
+
+func (h *hooked) OnShutdown(ctx context.Context) error {
+	h.rec.calls = append(h.rec.calls, "shutdown "+h.name)
+	return nil
+}
+
+type hookedCache struct{ hooked }
+type hookedService struct{ hooked }
+
+type hookedModule struct {
+	testModule
+	rec *hookRecorder
+}
+
+func (hm *hookedModule) OnInit(ctx context.Context) error {
+	hm.rec.calls = append(hm.rec.calls, "init module")
+	return nil
+}
+
+func (hm *hookedModule) OnShutdown(ctx context.Context) error {
+	hm.rec.calls = append(hm.rec.calls, "shutdown module")
+	return errors.New("module shutdown failed")
+}
+
+func TestModuleLifecycleHooks(t *testing.T) {
+	rec := &hookRecorder{}
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func(c *hookedCache) *hookedService {
+				return &hookedService{hooked{name: "service", rec: rec}}
+			}),
+			NewProvider(func() *hookedCache { return &hookedCache{hooked{name: "cache", rec: rec}} }),
+		},
+		modules: []Provider{
+			NewProvider(func() *hookedModule { return &hookedModule{rec: rec} }),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = m.Shutdown(context.Background())
+	var lerr *LifecycleError
+	if !errors.As(err, &lerr) || len(lerr.Errors) != 1 {
+		t.Fatalf("expected aggregated shutdown error, got: %v", err)
+	}
+
+	want := []string{
+		"init cache", "init service", "init module",
+		"shutdown module", "shutdown service", "shutdown cache",
+	}
+	if !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("wrong hooks order:\nwant %v\ngot  %v", want, rec.calls)
+	}
+}
+
+func TestModuleLifecycleInitError(t *testing.T) {
+	rec := &hookRecorder{}
+	errInit := errors.New("broker unavailable")
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *hookedCache { return &hookedCache{hooked{name: "cache", rec: rec}} }),
+			NewProvider(func() *hookedService {
+				return &hookedService{hooked{name: "service", rec: rec, initErr: errInit}}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.Init(context.Background()); !errors.Is(err, errInit) {
+		t.Fatalf("expected init error, got: %v", err)
+	}
+
+	want := []string{"init cache", "init service", "shutdown cache"}
+	if !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("wrong hooks order:\nwant %v\ngot  %v", want, rec.calls)
+	}
+}
+
+func TestModuleLifecycleHooksOnce(t *testing.T) {
+	rec := &hookRecorder{}
+
+	m, err := Bootstrap(&testModule{
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func() *hookedCache { return &hookedCache{hooked{name: "cache", rec: rec}} }),
+					},
+					exports: []Provider{NewExistingProvider(new(*hookedCache))},
+				}}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// re-exported instance is initialized once
+	if want := []string{"init cache", "shutdown cache"}; !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("wrong hooks:\nwant %v\ngot  %v", want, rec.calls)
+	}
+}
+
+func TestModuleLifecycleHooksLazy(t *testing.T) {
+	rec := &hookRecorder{}
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewLazyProvider(func() *hookedCache { return &hookedCache{hooked{name: "cache", rec: rec}} }),
+			NewProvider(func(c *hookedCache) *hookedService {
+				return &hookedService{hooked{name: "service", rec: rec}}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"init cache", "init service", "shutdown service", "shutdown cache"}
+	if !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("wrong hooks of lazily created instance:\nwant %v\ngot  %v", want, rec.calls)
+	}
+}
+
+type hookTimeoutModule struct {
+	testModule
+}
+
+func (hookTimeoutModule) Options() Options {
+	opts := NewOptions()
+	opts.HookTimeout = 10 * time.Millisecond
+	return opts
+}
+
+func TestModuleLifecycleHookTimeout(t *testing.T) {
+	rec := &hookRecorder{}
+
+	m, err := Bootstrap(&hookTimeoutModule{testModule{
+		imports: []Provider{
+			NewProvider(func() *hookedCache {
+				return &hookedCache{hooked{name: "cache", rec: rec, delay: time.Second}}
+			}),
+		},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.Init(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout error, got: %v", err)
+	}
+}
+
+type startedModule struct {
+	testModule
+	name     string
+	rec      *hookRecorder
+	startErr error
+}
+
+func (sm *startedModule) Start() error {
+	sm.rec.calls = append(sm.rec.calls, "start "+sm.name)
+	return sm.startErr
+}
+
+func (sm *startedModule) Stop(ctx context.Context) error {
+	sm.rec.calls = append(sm.rec.calls, "stop "+sm.name)
+	return ctx.Err()
+}
+
+type stoppedModule struct {
+	testModule
+	rec *hookRecorder
+}
+
+func (sm *stoppedModule) Stop() {
+	sm.rec.calls = append(sm.rec.calls, "stop legacy")
+}
+
+func startedTree(rec *hookRecorder, startErr error) *startedModule {
+	return &startedModule{name: "root", rec: rec, testModule: testModule{
+		modules: []Provider{
+			NewIsolatedProvider(func() *startedModule {
+				return &startedModule{name: "a", rec: rec, startErr: startErr, testModule: testModule{
+					modules: []Provider{
+						NewIsolatedProvider(func() *startedModule { return &startedModule{name: "a1", rec: rec} }),
+					},
+				}}
+			}),
+			NewProvider(func() *stoppedModule { return &stoppedModule{rec: rec} }),
+		},
+	}}
+}
+
+func TestModuleStartStop(t *testing.T) {
+	rec := &hookRecorder{}
+
+	m, err := Bootstrap(startedTree(rec, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Stop(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"start a1", "start a", "start root", "stop root", "stop legacy", "stop a", "stop a1"}
+	if !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("wrong start and stop order:\nwant %v\ngot  %v", want, rec.calls)
+	}
+
+	// modules are stopped once
+	if err := m.Stop(ctx); err != nil || len(rec.calls) != len(want) {
+		t.Errorf("modules should not be stopped again: %v, %v", err, rec.calls)
+	}
+
+	// stop errors are reported
+	if err := m.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancel()
+	var lerr *LifecycleError
+	if err := m.Stop(ctx); !errors.As(err, &lerr) || len(lerr.Errors) != 3 || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected stop errors, got: %v", err)
+	}
+}
+
+func TestModuleStartError(t *testing.T) {
+	rec := &hookRecorder{}
+	errStart := errors.New("start failed")
+
+	m, err := Bootstrap(startedTree(rec, errStart))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = m.Start()
+	if !errors.Is(err, errStart) {
+		t.Fatalf("expected start error, got: %v", err)
+	}
+
+	// already started modules are stopped
+	if want := []string{"start a1", "start a", "stop a1"}; !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("wrong start and stop order:\nwant %v\ngot  %v", want, rec.calls)
+	}
+}
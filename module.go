@@ -10,9 +10,12 @@ import (
 	"os/signal"
 	"reflect"
 	"strings"
-	"syscall"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-flow/flow/v2/di"
+	"github.com/go-flow/flow/v2/response"
 )
 
 // Module struct
@@ -20,18 +23,66 @@ type Module struct {
 	factory   ModuleFactory
 	name      string
 	options   Options
-	container di.Container
+	container *di.Container
 	parent    *Module
 	modules   []*Module
 	router    *Router
+	graph     moduleGraph
+	// qualifier and config of module created by ModuleProvider
+	qualifier string
+	config    interface{}
+	// exported holds instances exported by the module, registered to modules importing shared module
+	exported []exportedInstance
+
+	// mu guards cleanups and hooks of the root module, which are registered
+	// by all modules, possibly after bootstrap
+	mu sync.Mutex
+	// ready is set to 1 when root module serves requests, see Ready
+	ready int32
+	// started holds started modules in order of their start.
+	// started modules are registered only to the root module.
+	started []*Module
+	// cleanups holds cleanup functions returned by constructors of all modules.
+	// cleanup functions are registered only to the root module.
+	cleanups []func()
+	// hooks holds instances and module factories with lifecycle hooks in creation order.
+	// hooks are registered only to the root module.
+	hooks []interface{}
+	// overrides replace providers in the whole module tree, see Override.
+	// overrides are registered only to the root module.
+	overrides []*override
+	// strict enables strict export visibility, see StrictExports.
+	// globals holds instances of global providers imported by modules in strict mode.
+	strict  bool
+	globals *di.Container
+	// provided holds providers instantiated by the module
+	provided []*planNode
+	// shared holds modules imported by other modules, see IsolatedProvider.
+	// routed holds modules with registered routers.
+	// shared and routed modules are registered only to the root module.
+	shared map[moduleKey]*Module
+	routed map[*Module]bool
+	// deferred holds instances of planned providers of all modules, which are created
+	// after the whole module tree is planned. deferred instances are registered only to the root module.
+	deferred []deferredInstance
 }
 
-// NewModule creates new Module object
-func NewModule(factory ModuleFactory, container di.Container, parent *Module) (*Module, error) {
+// NewModule creates new Module object.
+// Bootstrap options are applied only to the root module.
+func NewModule(factory ModuleFactory, container *di.Container, parent *Module, options ...BootstrapOption) (*Module, error) {
+	return newModule(factory, container, parent, "", options...)
+}
+
+// newModule creates new Module object qualified by given name, see ModuleProvider
+func newModule(factory ModuleFactory, container *di.Container, parent *Module, qualifier string, options ...BootstrapOption) (_ *Module, err error) {
 	if factory == nil {
 		return nil, fmt.Errorf("factory object can not be nil")
 	}
 
+	if container == nil {
+		container = di.NewContainer()
+	}
+
 	// get module factory type
 	typ := reflect.TypeOf(factory)
 	// get module name
@@ -48,25 +99,60 @@ func NewModule(factory ModuleFactory, container di.Container, parent *Module) (*
 		name:      name[1:],
 		container: container,
 		parent:    parent,
+		qualifier: qualifier,
+	}
+	if qualifier != "" {
+		module.name += "[" + qualifier + "]"
 	}
 
-	// root module provides dependecies for all child modules.
+	// release resources acquired by constructors when module can not be created
 	if parent == nil {
-		// register all imports to module container
-		for _, p := range factory.ProvideImports() {
-			obj, err := p.Provide(&module.container)
+		defer func() {
 			if err != nil {
-				return nil, fmt.Errorf("unable to provide dependecy for module  `%s`. Error: %w", module.name, err)
+				module.Cleanup()
 			}
-			module.container.Add(obj)
+		}()
+	}
+
+	if parent == nil {
+		for _, opt := range options {
+			opt(module)
+		}
+		if err := module.validateOverrides(); err != nil {
+			return nil, fmt.Errorf("unable to bootstrap module `%s`. Error: %w", module.name, err)
+		}
+		// values registered before bootstrap are visible to all modules
+		if module.strict {
+			module.globals = container.Clone()
+		}
+	}
+
+	// root module provides dependecies for all child modules.
+	if parent == nil {
+		// register all imports to module container
+		if err := module.provideAll(ProviderKindImport, factory.ProvideImports(), module.container); err != nil {
+			return nil, fmt.Errorf("unable to provide dependecy for module  `%s`. Error: %w", module.name, err)
 		}
 	}
 
 	// register all dependecies (imported modules)
 	for _, p := range factory.ProvideModules() {
 
+		// modules imported by several modules using the same constructor are shared, unless they are isolated
+		shared := !isolated(p)
+		if shared {
+			sm, err := module.sharedModule(p, nil)
+			if err != nil {
+				return nil, fmt.Errorf("unable to provide dependecy module for module `%s`. Error: %w", module.name, err)
+			}
+			if sm != nil {
+				module.importShared(sm)
+				continue
+			}
+		}
+
 		// provide module factory object
-		dep, err := p.Provide(&module.container)
+		dep, err := p.Provide(module)
 		if err != nil {
 			return nil, fmt.Errorf("unable to provide dependecy module for module `%s`. Error: %w", module.name, err)
 		}
@@ -75,45 +161,75 @@ func NewModule(factory ModuleFactory, container di.Container, parent *Module) (*
 		if !ok {
 			return nil, fmt.Errorf("unable to provide dependecy module for module `%s`. Error: %w", module.name, errors.New("provided constructor did not create instance of ModuleFactory interface"))
 		}
+
+		// identity of modules created by opaque providers is known after they are provided
+		if shared {
+			sm, err := module.sharedModule(p, depFac)
+			if err != nil {
+				return nil, fmt.Errorf("unable to provide dependecy module for module `%s`. Error: %w", module.name, err)
+			}
+			if sm != nil {
+				module.importShared(sm)
+				continue
+			}
+		}
+		// configurable modules resolve their own config
+		container, qualifier, config := module.childContainer(), "", interface{}(nil)
+		if mp, ok := p.(ModuleProvider); ok {
+			qualifier, config = mp.Name(), mp.Config()
+			if err := replaceValues(container, config); err != nil {
+				return nil, fmt.Errorf("unable to provide dependecy module for module `%s`. Error: %w", module.name, err)
+			}
+		}
+
 		// create module object
-		m, err := NewModule(depFac, module.container.Clone(), module)
+		m, err := newModule(depFac, container, module, qualifier)
 		if err != nil {
 			return nil, fmt.Errorf("unable to provide dependecy module for module `%s`. Error: %w", module.name, err)
 		}
 
 		// check if imported module exports any functionality
-		for _, p := range depFac.ProvideExports() {
-			exp, err := p.Provide(&m.container)
-			if err != nil {
-				return nil, fmt.Errorf("unable to provide exported dependecy for module `%s`. Error: %w", m.name, err)
-			}
-			// add feature to module
-			m.container.Add(exp)
-			// add feature to parent module
-			module.container.Add(exp)
+		// exported features are added to the module and to the parent module
+		if err := m.provideAll(ProviderKindExport, depFac.ProvideExports(), m.container, module.container); err != nil {
+			return nil, fmt.Errorf("unable to provide exported dependecy for module `%s`. Error: %w", m.name, err)
 		}
 
+		m.config = config
 		module.modules = append(module.modules, m)
+		if shared {
+			module.addShared(p, m)
+		}
 	}
 
 	// import all dependecies for child modules
 	// child modules first register their child modules,
 	// and then they provide functionality internally which can depend on child modules
 	if parent != nil {
-		for _, p := range factory.ProvideImports() {
-			dep, err := p.Provide(&module.container)
-			if err != nil {
-				return nil, fmt.Errorf("unable to provide dependecy for module  `%s`. Error: %w", module.name, err)
-			}
-
-			module.container.Add(dep)
+		if err := module.provideAll(ProviderKindImport, factory.ProvideImports(), module.container); err != nil {
+			return nil, fmt.Errorf("unable to provide dependecy for module  `%s`. Error: %w", module.name, err)
 		}
 	}
 
 	// ad the di injector to the container
-	module.container.Add(&module.container)
+	module.container.Add(module.container)
+
+	// child modules are initialized after their dependencies
+	if !module.IsRoot() {
+		module.addDeferred(deferredInstance{module: module})
+	}
 
 	if module.IsRoot() {
+		if err := module.construct(); err != nil {
+			return nil, err
+		}
+
+		if err := module.unusedOverrides(); err != nil {
+			return nil, fmt.Errorf("unable to bootstrap module `%s`. Error: %w", module.name, err)
+		}
+
+		if err := module.container.InjectFields(factory); err != nil {
+			return nil, fmt.Errorf("unable to inject dependencies to module `%s`. Error: %w", module.name, err)
+		}
 		module.container.InjectDeps(factory)
 
 		// get module options
@@ -127,6 +243,8 @@ func NewModule(factory ModuleFactory, container di.Container, parent *Module) (*
 			module.options = module.parent.options
 		}
 
+		module.addHooks(factory)
+
 		module.router = NewRouterWithOptions(module.options.RouterOptions)
 		if err := module.registerRouters(module.router); err != nil {
 			return nil, fmt.Errorf("unable to register routers for module `%s`. Error: %w", module.name, err)
@@ -136,12 +254,149 @@ func NewModule(factory ModuleFactory, container di.Container, parent *Module) (*
 	return module, nil
 }
 
+// provideAll instantiates providers in dependency order
+// and registers created instances to given containers
+func (m *Module) provideAll(kind string, providers []Provider, containers ...*di.Container) error {
+	nodes, err := m.plan(kind, providers)
+	if err != nil {
+		return err
+	}
+
+	// deferred instances of the batch resolve dependencies from the module container
+	// as it is when all instances of the batch are registered
+	injector := &snapshotInjector{module: m}
+	defer injector.snapshot()
+
+	for _, n := range nodes {
+		obj, err := m.provide(kind, n, injector)
+		if err != nil {
+			return err
+		}
+		for _, c := range containers {
+			if kind == ProviderKindExport && c != m.container {
+				m.registerExport(c, n.provider, obj)
+				continue
+			}
+			register(c, n.provider, obj)
+		}
+		if kind == ProviderKindExport {
+			m.exported = append(m.exported, exportedInstance{provider: n.provider, obj: obj})
+		}
+		if gp, ok := n.provider.(GlobalProvider); ok && gp.Global() {
+			m.registerGlobal(n.provider, obj, containers)
+		}
+		if l, ok := obj.(*di.Lazy); ok {
+			m.addCleanup(l.Cleanup)
+			l.OnCreate(m.addHooks)
+		}
+		m.addHooks(obj)
+	}
+	return nil
+}
+
+// deferredInstance is an instance of planned provider created by construct.
+// Factory of the module is registered to lifecycle hooks when lazy is nil.
+type deferredInstance struct {
+	module *Module
+	kind   string
+	lazy   *di.Lazy
+}
+
+// snapshotInjector provides instances with dependencies resolved from
+// the module container as it was when the batch of providers was planned
+type snapshotInjector struct {
+	module    *Module
+	container *di.Container
+	deferred  bool
+}
+
+// snapshot clones the module container when instances of the batch are deferred
+func (si *snapshotInjector) snapshot() {
+	if si.deferred {
+		si.container = si.module.container.Clone()
+	}
+}
+
+// Provide resolves dependencies from the module container until the batch is registered,
+// eg. when instance of an interface type depending on deferred instances is created
+func (si *snapshotInjector) Provide(constructor interface{}) (interface{}, error) {
+	if si.container == nil {
+		return si.module.Provide(constructor)
+	}
+	return si.module.provideFrom(si.container, constructor)
+}
+
+// provide instantiates planned provider. Instances of providers with constructor are
+// registered as Lazy values and created by construct, after the whole module tree is planned,
+// so missing dependencies of any module are reported before constructors are invoked.
+func (m *Module) provide(kind string, n *planNode, injector *snapshotInjector) (interface{}, error) {
+	// scoped instances are created on demand, aliases and decorators are applied on demand,
+	// instances of opaque providers are known only after instantiation
+	// and instances of interface types are registered by their dynamic type
+	if _, alias := n.provider.(*aliasProvider); alias || n.constructor == nil || n.scope != "" || n.decorator || n.dynamic() {
+		return n.provider.Provide(m)
+	}
+
+	p := n.provider
+	injector.deferred = true
+	l := di.NewLazyFunc(n.constructor, func() (interface{}, error) {
+		return p.Provide(injector)
+	})
+	m.addDeferred(deferredInstance{module: m, kind: kind, lazy: l})
+	return l, nil
+}
+
+// addDeferred registers instance created after the whole module tree is planned
+func (m *Module) addDeferred(d deferredInstance) {
+	root := m.root()
+	root.deferred = append(root.deferred, d)
+}
+
+// construct creates instances of planned providers of the whole module tree in planned order
+// and registers lifecycle hooks of module factories after instances they depend on.
+// Instances injected to module factories are created already when factories are created.
+func (m *Module) construct() error {
+	deferred := m.deferred
+	m.deferred = nil
+
+	for _, d := range deferred {
+		if d.lazy == nil {
+			m.addHooks(d.module.factory)
+			continue
+		}
+		if _, err := d.lazy.Value(d.module.container); err != nil {
+			if d.kind == ProviderKindExport {
+				return fmt.Errorf("unable to provide exported dependecy for module `%s`. Error: %w", d.module.name, err)
+			}
+			return fmt.Errorf("unable to provide dependecy for module  `%s`. Error: %w", d.module.name, err)
+		}
+	}
+	return nil
+}
+
+// register adds instance created by provider to container
+func register(container *di.Container, p Provider, obj interface{}) {
+	if np, ok := p.(NamedProvider); ok {
+		container.AddNamed(np.Name(), obj)
+		return
+	}
+	if gp, ok := p.(GroupProvider); ok {
+		container.AddGroup(gp.Group(), obj)
+		return
+	}
+	container.Add(obj)
+}
+
 func (m *Module) registerRouters(parent *Router) error {
+	var moduleInterceptors []Interceptor
+	if mi, ok := m.factory.(ModuleInterceptor); ok {
+		moduleInterceptors = mi.Interceptors()
+	}
 
 	// initialize module routers
 	for _, p := range m.factory.ProvideRouters() {
 		// get router provider
-		rp, err := p.Provide(&m.container)
+		rp, err := p.Provide(m)
 		if err != nil {
 			return fmt.Errorf("unable to register router provider for module  `%s`. Error: %w", m.name, err)
 		}
@@ -153,6 +408,15 @@ func (m *Module) registerRouters(parent *Router) error {
 		}
 
 		group := parent.Group(rf.Path(), rf.Middlewares()...)
+		gr := &GraphRouter{
+			Type: reflect.TypeOf(rf).String(),
+			Path: group.basePath,
+		}
+		m.graph.routers = append(m.graph.routers, gr)
+		group.Intercept(moduleInterceptors...)
+		if ri, ok := rf.(RouterInterceptor); ok {
+			group.Intercept(ri.Interceptors()...)
+		}
 
 		// for root modules create root routers with shared tree
 		if m.IsRoot() {
@@ -162,7 +426,7 @@ func (m *Module) registerRouters(parent *Router) error {
 		// get all action handlers
 		for _, p := range rf.ProvideHandlers() {
 			// provide action handler
-			ah, err := p.Provide(&m.container)
+			ah, err := p.Provide(m)
 			if err != nil {
 				return fmt.Errorf("unable to register action handler for module  `%s`. Error: %w", m.name, err)
 			}
@@ -173,12 +437,28 @@ func (m *Module) registerRouters(parent *Router) error {
 				return fmt.Errorf("unable to register action handler for module  `%s`. Error: %w", m.name, errors.New("provided constructor did not create instance of ActionHandler interface"))
 			}
 
-			group.Handle(handler.Method(), handler.Path(), handler.Handle, handler.Middlewares()...)
+			handle := handler.Handle
+			if si := m.container.ScopedInjector(handler); si.CanInject {
+				handle = m.requestScopedHandler(si)
+			}
+			if hi, ok := handler.(HandlerInterceptor); ok {
+				handle = intercept(handle, hi.Interceptors())
+			}
+
+			group.Handle(handler.Method(), handler.Path(), handle, handler.Middlewares()...)
+			gr.Handlers = append(gr.Handlers, &GraphHandler{
+				Type:   reflect.TypeOf(handler).String(),
+				Method: handler.Method(),
+				Path:   joinPaths(group.basePath, handler.Path()),
+			})
 		}
 
 		// check if sub routers should be registered for given router
 		if rf.RegisterSubRouters() {
 			for _, module := range m.modules {
+				if !m.routeOnce(module) {
+					continue
+				}
 				if err := module.registerRouters(group); err != nil {
 					return fmt.Errorf("unable to register routers of imported module `%s`. Error: %w", module.name, err)
 				}
@@ -189,64 +469,282 @@ func (m *Module) registerRouters(parent *Router) error {
 	return nil
 }
 
+// requestScopedHandler returns handler function which handles every request with
+// a copy of action handler holding request scoped dependencies of the request.
+// Request scoped dependencies are released after the Response is written.
+func (m *Module) requestScopedHandler(si *di.StructInjector) HandlerFunc {
+	return func(r *http.Request) Response {
+		scope := m.container.NewRequestScope(r)
+
+		v, err := si.InjectScope(scope)
+		if err != nil {
+			scope.Close()
+			return ResponseError(http.StatusInternalServerError, fmt.Errorf("unable to resolve request scoped dependencies for module `%s`. Error: %w", m.name, err))
+		}
+
+		res := v.Interface().(ActionHandler).Handle(r)
+		if res == nil {
+			scope.Close()
+			return nil
+		}
+		return response.WithCleanup(res, scope.Close)
+	}
+}
+
 func (m *Module) IsRoot() bool {
 	return m.parent == nil
 }
 
+// Provide invokes constructor with dependencies injected from module container.
+// Cleanup function returned by constructor is registered to the root module
+// and invoked by Cleanup method.
+func (m *Module) Provide(constructor interface{}) (interface{}, error) {
+	return m.provideFrom(m.container, constructor)
+}
+
+// provideFrom invokes constructor with dependencies resolved from given container
+func (m *Module) provideFrom(c *di.Container, constructor interface{}) (interface{}, error) {
+	val, cleanup, err := c.ProvideWithCleanup(constructor)
+	if err != nil {
+		if verr := m.visibilityError(err); verr != nil {
+			return nil, verr
+		}
+		return nil, fmt.Errorf("%w in module chain %s", err, m.chain())
+	}
+
+	if cleanup != nil {
+		m.addCleanup(cleanup)
+	}
+	return val, nil
+}
+
+// Invoke calls function with parameters resolved from module container,
+// eg. to run migrations or CLI commands after bootstrap.
+// See di.Container.Invoke for supported functions.
+func (m *Module) Invoke(function interface{}) error {
+	if err := m.container.Invoke(function); err != nil {
+		return fmt.Errorf("unable to invoke function in module chain %s. Error: %w", m.chain(), err)
+	}
+	return nil
+}
+
+// Cleanup invokes cleanup functions returned by constructors
+// in reverse order of their registration
+func (m *Module) Cleanup() {
+	root := m.root()
+	root.mu.Lock()
+	cleanups := root.cleanups
+	root.cleanups = nil
+	root.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// addCleanup registers cleanup function to the root module
+func (m *Module) addCleanup(cleanup func()) {
+	root := m.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	root.cleanups = append(root.cleanups, cleanup)
+}
+
+func (m *Module) root() *Module {
+	for m.parent != nil {
+		m = m.parent
+	}
+	return m
+}
+
+// chain returns names of modules from the root module to the current one
+func (m *Module) chain() string {
+	if m.parent == nil {
+		return m.name
+	}
+	return m.parent.chain() + " -> " + m.name
+}
+
 // Serve the application at the specified address/port and listen for OS
 // interrupt and kill signals and will attempt to stop the application
-// gracefully.
+// gracefully. Application is served over TLS when TLSOptions are set.
+// Provided instances are initialized, see Init, before modules are started, see Start.
+//
+// When shutdown signal is received the module is marked as not ready, see Ready,
+// and server stops accepting new connections. Open connections are drained within
+// Options.ShutdownTimeout and closed afterwards, then modules are stopped within Options.StopTimeout.
+// Serve returns nil when application is stopped gracefully.
 func (m *Module) Serve() error {
 
 	if m.router == nil {
 		return fmt.Errorf("unable to serve module `%s`. Error: %w", m.name, errors.New("http router is not initialized"))
 	}
 
-	if s, ok := m.factory.(ModuleStarter); ok {
-		if err := s.Start(); err != nil {
-			return fmt.Errorf("unable to start module `%s`. Error: %w", m.name, err)
-		}
+	if err := m.Init(context.Background()); err != nil {
+		m.Cleanup()
+		return fmt.Errorf("unable to initialize module `%s`. Error: %w", m.name, err)
+	}
+
+	if err := m.Start(); err != nil {
+		return m.abortServe(err)
 	}
 
 	// create http server
-	srv := http.Server{
-		Handler: m.router,
+	srv, reloader, err := m.newServer()
+	if err != nil {
+		return m.abortServe(err)
 	}
 
-	// make interrupt signal channel
+	// reload TLS certificate when files change or reload signal is received
+	if reloader != nil {
+		signals := m.options.TLSReloadSignals
+		if len(signals) == 0 {
+			signals = defaultTLSReloadSignals
+		}
+		stop := reloader.watch(m.options.TLSReloadInterval, signals, m.options.ErrorLog)
+		defer stop()
+	}
+
+	// make shutdown signal channel
+	signals := m.options.ShutdownSignals
+	if len(signals) == 0 {
+		signals = defaultShutdownSignals
+	}
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(c, signals...)
+	defer signal.Stop(c)
 
-	// listen for interrupt signals
-	go func() {
-		<-c
+	lis, err := m.listen()
+	if err != nil {
+		return m.abortServe(err)
+	}
 
-		if s, ok := m.factory.(ModuleStopper); ok {
-			s.Stop()
-		}
+	// closed when shutdown process is finished
+	done := make(chan struct{})
+	// closed when server fails to serve
+	failed := make(chan struct{})
+	// error returned by shutdown process
+	var shutdownErr error
 
-		if err := srv.Shutdown(context.Background()); err != nil {
-			panic(fmt.Errorf("unable to gracefully shutdown HTTP.Server. Error: %w", err))
+	// listen for shutdown signals
+	go func() {
+		defer close(done)
+		select {
+		case <-c:
+		case <-failed:
+			return
 		}
+		shutdownErr = m.shutdownServer(srv)
 	}()
 
-	// get listen address from options
-	addr := m.options.Addr
+	//start accepting incomming requests
+	m.setReady(true)
+	if srv.TLSConfig != nil {
+		err = srv.ServeTLS(lis, "", "")
+	} else {
+		err = srv.Serve(lis)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		<-done
+		return shutdownErr
+	}
 
+	m.setReady(false)
+	close(failed)
+	<-done
+	return m.abortServe(err)
+}
+
+// listen creates network listener for the address from module options.
+// Addresses with `unix:` prefix are unix socket paths.
+func (m *Module) listen() (net.Listener, error) {
+	network, addr := "tcp", m.options.Addr
 	if strings.HasPrefix(addr, "unix:") {
-		// create unix network listener
-		lis, err := net.Listen(addr, addr[5:])
-		if err != nil {
-			return err
-		}
-		// start accepting incomming requests on listener
-		return srv.Serve(lis)
+		network, addr = "unix", addr[5:]
+	} else if addr == "" {
+		addr = ":http"
 	}
+	return net.Listen(network, addr)
+}
 
-	// assign address to http server
-	srv.Addr = addr
+// shutdownServer drains open connections of the server, stops modules and
+// invokes shutdown hooks and cleanup functions.
+// Connections which are not closed within shutdown timeout are closed forcibly.
+func (m *Module) shutdownServer(srv *http.Server) error {
+	m.setReady(false)
+	if delay := m.options.ShutdownDelay; delay > 0 {
+		time.Sleep(delay)
+	}
 
-	//start accepting incomming requests
-	return srv.ListenAndServe()
+	var errs []error
+	ctx, cancel := timeoutContext(m.options.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+		errs = append(errs, fmt.Errorf("unable to gracefully shutdown HTTP server, open connections are closed. Error: %w", err))
+	}
+
+	// modules are stopped when they do not serve requests anymore
+	stopCtx, stopCancel := timeoutContext(m.options.StopTimeout)
+	defer stopCancel()
+	if err := m.Stop(stopCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		errs = append(errs, err)
+	}
 
+	m.Cleanup()
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("unable to shutdown module `%s`. Error: %w", m.name, &LifecycleError{Errors: errs})
+}
+
+// timeoutContext returns context limited by given timeout, zero timeout does not limit the context
+func timeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// abortServe releases resources of started modules and initialized instances
+// when server fails to serve
+func (m *Module) abortServe(err error) error {
+	if serr := m.Stop(context.Background()); serr != nil {
+		err = fmt.Errorf("%w; %v", err, serr)
+	}
+	if serr := m.Shutdown(context.Background()); serr != nil {
+		err = fmt.Errorf("%w; unable to shutdown module `%s`. Error: %v", err, m.name, serr)
+	}
+	m.Cleanup()
+	return err
+}
+
+// Ready returns true when application is serving requests and it is not shutting down
+func (m *Module) Ready() bool {
+	return atomic.LoadInt32(&m.root().ready) == 1
+}
+
+// ReadinessHandler returns handler responding with 204 No Content when application is ready
+// and with 503 Service Unavailable otherwise, eg. for load balancer health checks
+func (m *Module) ReadinessHandler() HandlerFunc {
+	return func(r *http.Request) Response {
+		if !m.Ready() {
+			return ResponseError(http.StatusServiceUnavailable, errors.New("application is not ready"))
+		}
+		return ResponseNoContent()
+	}
+}
+
+func (m *Module) setReady(ready bool) {
+	var v int32
+	if ready {
+		v = 1
+	}
+	atomic.StoreInt32(&m.root().ready, v)
 }
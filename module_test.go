@@ -0,0 +1,530 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-flow/flow/v2/di"
+)
+
+// testModule is configurable ModuleFactory used by module tests
+type testModule struct {
+	imports []Provider
+	exports []Provider
+	modules []Provider
+	routers []Provider
+}
+
+func (tm *testModule) ProvideImports() []Provider { return tm.imports }
+func (tm *testModule) ProvideExports() []Provider { return tm.exports }
+func (tm *testModule) ProvideModules() []Provider { return tm.modules }
+func (tm *testModule) ProvideRouters() []Provider { return tm.routers }
+
+// childModule is configurable ModuleFactory used as imported module by module tests
+type childModule struct {
+	testModule
+}
+
+type testConfig struct {
+	DSN string
+}
+
+type testDB struct {
+	dsn    string
+	closed bool
+}
+
+type testRepo struct {
+	db *testDB
+}
+
+func TestModuleProvideWithError(t *testing.T) {
+	errConnect := errors.New("connection refused")
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testConfig { return &testConfig{DSN: "db"} }),
+			NewProvider(func(cfg *testConfig) (*testDB, error) { return nil, errConnect }),
+		},
+	})
+
+	if !errors.Is(err, errConnect) {
+		t.Fatalf("expected constructor error to be propagated, got: %v", err)
+	}
+}
+
+func TestModuleProvideWithCleanup(t *testing.T) {
+	var calls []string
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() (*testDB, func(), error) {
+				return &testDB{}, func() { calls = append(calls, "db") }, nil
+			}),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func(db *testDB) (*testRepo, func(), error) {
+							return &testRepo{db: db}, func() { calls = append(calls, "repo") }, nil
+						}),
+					},
+				}}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m.Cleanup()
+	if want := []string{"repo", "db"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("wrong cleanup order: want %v, got %v", want, calls)
+	}
+}
+
+func TestModuleCleanupOnBootstrapError(t *testing.T) {
+	db := &testDB{}
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() (*testDB, func(), error) {
+				return db, func() { db.closed = true }, nil
+			}),
+			NewProvider(func(db *testDB) (*testRepo, error) { return nil, errors.New("migration failed") }),
+		},
+	})
+	if err == nil {
+		t.Fatal("expected bootstrap error")
+	}
+	if !db.closed {
+		t.Error("expected cleanup function to be invoked when bootstrap fails")
+	}
+}
+
+func TestModuleMissingDependencyError(t *testing.T) {
+	_, err := Bootstrap(&testModule{
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func(db *testDB) *testRepo { return &testRepo{db: db} }),
+					},
+				}}
+			}),
+		},
+	})
+
+	var depErr *di.DependencyError
+	if !errors.As(err, &depErr) {
+		t.Fatalf("expected dependency error, got: %v", err)
+	}
+	if depErr.Type != reflect.TypeOf(&testDB{}) {
+		t.Errorf("wrong missing type: %s", depErr.Type)
+	}
+
+	msg := err.Error()
+	for _, want := range []string{"`*flow.testDB`", "module_test.go:", "flow.testModule -> flow.childModule"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error message %q does not contain %q", msg, want)
+		}
+	}
+}
+
+func TestModuleInvalidConstructor(t *testing.T) {
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() {}),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "has to return") {
+		t.Fatalf("expected invalid constructor error, got: %v", err)
+	}
+}
+
+type testDBParams struct {
+	di.In
+	Primary *testDB
+	Replica *testDB `inject:"replica"`
+}
+
+func TestModuleNamedProviders(t *testing.T) {
+	var params testDBParams
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB { return &testDB{dsn: "primary"} }),
+			NewNamedProvider("replica", func() *testDB { return &testDB{dsn: "replica"} }),
+			NewProvider(func(p testDBParams) *testRepo {
+				params = p
+				return &testRepo{db: p.Replica}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if params.Primary.dsn != "primary" || params.Replica.dsn != "replica" {
+		t.Errorf("wrong resolved dependencies: %+v, %+v", params.Primary, params.Replica)
+	}
+}
+
+type testUser struct {
+	name   string
+	closed bool
+}
+
+// userResponse writes the user when it is handled, after action handler returns
+type userResponse struct {
+	user *testUser
+	dsn  string
+}
+
+func (userResponse) Status() int { return http.StatusOK }
+func (r userResponse) Handle(w http.ResponseWriter, _ *http.Request) error {
+	if r.user.closed {
+		return errors.New("request scoped user is released")
+	}
+	_, err := io.WriteString(w, `"`+r.user.name+"@"+r.dsn+`"`)
+	return err
+}
+
+type scopedRouter struct{}
+
+func (scopedRouter) Path() string                         { return "/" }
+func (scopedRouter) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (scopedRouter) RegisterSubRouters() bool             { return false }
+func (scopedRouter) ProvideHandlers() []Provider {
+	return []Provider{NewProvider(func(repo *testRepo) *scopedHandler { return &scopedHandler{Repo: repo} })}
+}
+
+type scopedHandler struct {
+	Repo *testRepo
+	User *testUser
+}
+
+func (scopedHandler) Method() string                       { return http.MethodGet }
+func (scopedHandler) Path() string                         { return "/" }
+func (scopedHandler) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (h *scopedHandler) Handle(r *http.Request) Response {
+	return userResponse{user: h.User, dsn: h.Repo.db.dsn}
+}
+
+func TestModuleRequestProvider(t *testing.T) {
+	closed := 0
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewRequestProvider(func(r *http.Request, db *testDB) (*testUser, func(), error) {
+				u := &testUser{name: r.Header.Get("X-User")}
+				return u, func() {
+					u.closed = true
+					closed++
+				}, nil
+			}),
+			NewProvider(func() *testDB { return &testDB{dsn: "db"} }),
+			NewProvider(func(db *testDB) *testRepo { return &testRepo{db: db} }),
+		},
+		routers: []Provider{
+			NewProvider(func() *scopedRouter { return &scopedRouter{} }),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, user := range []string{"alice", "bob"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		m.router.ServeHTTP(w, r)
+
+		if got, want := w.Body.String(), `"`+user+`@db"`; got != want {
+			t.Errorf("wrong body: want %s, got %s", want, got)
+		}
+	}
+	if closed != 2 {
+		t.Errorf("expected cleanup per request, got %d", closed)
+	}
+
+	g := m.Graph()
+	if p := g.Modules[0].Providers[0]; p.Scope != ProviderScopeRequest || p.Type != "*flow.testUser" {
+		t.Errorf("wrong request provider in graph: %+v", p)
+	}
+}
+
+func TestModuleInvoke(t *testing.T) {
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB { return &testDB{dsn: "db"} }),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var dsn string
+	if err := m.Invoke(func(db *testDB) { dsn = db.dsn }); err != nil || dsn != "db" {
+		t.Errorf("function was not invoked with module dependencies: %v", err)
+	}
+
+	err = m.Invoke(func(*testRepo) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "missing dependency `*flow.testRepo`") {
+		t.Errorf("expected missing dependency error, got: %v", err)
+	}
+}
+
+type taggedRepo struct {
+	db     *testDB     `inject:""`
+	config *testConfig `inject:"optional"`
+}
+
+func TestModuleTaggedFields(t *testing.T) {
+	var repo *taggedRepo
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *taggedRepo {
+				repo = &taggedRepo{}
+				return repo
+			}),
+			NewProvider(func() *testDB { return &testDB{dsn: "db"} }),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.db == nil || repo.db.dsn != "db" || repo.config != nil {
+		t.Errorf("wrong injected fields: %+v", repo)
+	}
+
+	_, err = Bootstrap(&testModule{
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func() *taggedRepo { return &taggedRepo{} }),
+					},
+				}}
+			}),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing dependency `*flow.testDB` for field flow.taggedRepo.db") {
+		t.Fatalf("expected missing field dependency error, got: %v", err)
+	}
+}
+
+type servedModule struct {
+	testModule
+	opts Options
+}
+
+func (sm *servedModule) Options() Options { return sm.opts }
+
+type blockingRouter struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (blockingRouter) Path() string                         { return "/" }
+func (blockingRouter) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (blockingRouter) RegisterSubRouters() bool             { return false }
+func (br blockingRouter) ProvideHandlers() []Provider {
+	return []Provider{NewValueProvider(&blockingHandler{br})}
+}
+
+type blockingHandler struct {
+	blockingRouter
+}
+
+func (blockingHandler) Method() string                       { return http.MethodGet }
+func (blockingHandler) Path() string                         { return "/block" }
+func (blockingHandler) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (bh blockingHandler) Handle(r *http.Request) Response {
+	close(bh.entered)
+	<-bh.release
+	return ResponseNoContent()
+}
+
+// serveModule serves module in background and waits until it is ready
+func serveModule(t *testing.T, m *Module) <-chan error {
+	served := make(chan error, 1)
+	go func() {
+		served <- m.Serve()
+	}()
+
+	for start := time.Now(); !m.Ready(); time.Sleep(5 * time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("module is not ready")
+		}
+	}
+	return served
+}
+
+func servedOptions(t *testing.T) Options {
+	opts := NewOptions()
+	opts.Addr = "unix:" + filepath.Join(t.TempDir(), "flow.sock")
+	opts.ShutdownSignals = []os.Signal{syscall.SIGUSR1}
+	return opts
+}
+
+func TestModuleServeShutdown(t *testing.T) {
+	rec := &hookRecorder{}
+	m, err := Bootstrap(&servedModule{
+		testModule: testModule{
+			imports: []Provider{
+				NewProvider(func() *hookedCache { return &hookedCache{hooked{name: "cache", rec: rec}} }),
+			},
+		},
+		opts: servedOptions(t),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	served := serveModule(t, m)
+	ready := m.ReadinessHandler()
+	if status := ready(nil).Status(); status != http.StatusNoContent {
+		t.Errorf("wrong readiness status of served module: %d", status)
+	}
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("expected nil error on graceful shutdown, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server is not stopped")
+	}
+
+	if status := ready(nil).Status(); m.Ready() || status != http.StatusServiceUnavailable {
+		t.Errorf("module should not be ready after shutdown: %d", status)
+	}
+	if want := []string{"init cache", "shutdown cache"}; !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("wrong lifecycle hooks: %v", rec.calls)
+	}
+}
+
+// drainedModule checks that the server does not accept connections when modules are stopped
+type drainedModule struct {
+	testModule
+	addr    string
+	dialErr error
+}
+
+func (dm *drainedModule) Stop(ctx context.Context) error {
+	conn, err := net.Dial("unix", dm.addr)
+	if err == nil {
+		conn.Close()
+	}
+	dm.dialErr = err
+	return nil
+}
+
+func TestModuleServeStopAfterDrain(t *testing.T) {
+	opts := servedOptions(t)
+	dm := &drainedModule{addr: opts.Addr[5:]}
+	m, err := Bootstrap(&servedModule{
+		testModule: testModule{
+			modules: []Provider{NewValueProvider(dm)},
+		},
+		opts: opts,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	served := serveModule(t, m)
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	if err := <-served; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dm.dialErr == nil {
+		t.Error("modules should be stopped after server stops accepting connections")
+	}
+}
+
+func TestModuleServeStartError(t *testing.T) {
+	rec := &hookRecorder{}
+	errStart := errors.New("start failed")
+	m, err := Bootstrap(&servedModule{
+		testModule: testModule{
+			imports: []Provider{
+				NewProvider(func() *hookedCache { return &hookedCache{hooked{name: "cache", rec: rec}} }),
+				NewProvider(func() (*testDB, func(), error) {
+					return &testDB{}, func() { rec.calls = append(rec.calls, "cleanup db") }, nil
+				}),
+			},
+			modules: []Provider{
+				NewProvider(func() *startedModule { return &startedModule{name: "a", rec: rec, startErr: errStart} }),
+			},
+		},
+		opts: servedOptions(t),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.Serve(); !errors.Is(err, errStart) {
+		t.Fatalf("expected start error, got: %v", err)
+	}
+
+	// instances are initialized before modules are started, shut down and cleaned up when start fails
+	if want := []string{"init cache", "start a", "shutdown cache", "cleanup db"}; !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("wrong lifecycle order:\nwant %v\ngot  %v", want, rec.calls)
+	}
+}
+
+func TestModuleServeShutdownTimeout(t *testing.T) {
+	br := blockingRouter{entered: make(chan struct{}), release: make(chan struct{})}
+	defer close(br.release)
+
+	opts := servedOptions(t)
+	opts.ShutdownTimeout = 50 * time.Millisecond
+	m, err := Bootstrap(&servedModule{
+		testModule: testModule{
+			routers: []Provider{NewValueProvider(br)},
+		},
+		opts: opts,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	served := serveModule(t, m)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", opts.Addr[5:])
+		},
+	}}
+	go client.Get("http://flow/block")
+	<-br.entered
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+
+	select {
+	case err := <-served:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected shutdown timeout error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("open connections are not closed after shutdown timeout")
+	}
+}
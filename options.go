@@ -1,7 +1,17 @@
 package flow
 
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
 const (
 	defaultEnv     = "development"
+	productionEnv  = "production"
 	defaultName    = "HiveApp"
 	defaultAddr    = "0.0.0.0:5000"
 	defaultVersion = "v0.0.0"
@@ -11,13 +21,38 @@ const (
 	defaultHandleMethodNotAllowed = true
 	defaultHandleOptions          = true
 
+	defaultHookTimeout     = 15 * time.Second
+	defaultShutdownTimeout = 30 * time.Second
+	defaultStopTimeout     = 15 * time.Second
+
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
+	defaultMaxHeaderBytes    = 1 << 20
+
+	productionReadHeaderTimeout = 5 * time.Second
+	productionReadTimeout       = 30 * time.Second
+	productionWriteTimeout      = time.Minute
+	productionIdleTimeout       = time.Minute
+	productionMaxHeaderBytes    = 64 << 10
+
+	defaultTLSReloadInterval = time.Minute
+
 	default404Body = "404 page not found"
 	default405Body = "405 method not allowed"
 )
 
+var (
+	// defaultShutdownSignals are signals which stop served application by default
+	defaultShutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	// defaultTLSReloadSignals are signals which reload TLS certificate by default
+	defaultTLSReloadSignals = []os.Signal{syscall.SIGHUP}
+)
+
 // Options holds application configuration Options
 type Options struct {
 	RouterOptions
+	ServerOptions
+	TLSOptions
 	initialized      bool
 	Env              string
 	Name             string
@@ -26,6 +61,19 @@ type Options struct {
 	ModuleSuffix     string
 	ControllerSuffix string
 	ControllerIndex  string
+	// HookTimeout limits the duration of each OnInit and OnShutdown hook
+	HookTimeout time.Duration
+	// ShutdownTimeout limits the duration of draining open connections
+	// during graceful shutdown, connections are closed forcibly afterwards
+	ShutdownTimeout time.Duration
+	// StopTimeout limits the duration of stopping modules after connections are drained,
+	// see ModuleGracefulStopper
+	StopTimeout time.Duration
+	// ShutdownDelay delays draining after application is marked as not ready,
+	// so load balancers stop routing new requests to it
+	ShutdownDelay time.Duration
+	// ShutdownSignals stop served application, os.Interrupt and SIGTERM by default
+	ShutdownSignals []os.Signal
 }
 
 // RouterOptions holds router configuration Options
@@ -39,6 +87,74 @@ type RouterOptions struct {
 	Body405                string
 }
 
+// ServerOptions holds http server configuration Options, see http.Server.
+// Zero timeouts and limits are replaced by defaults of Options.Env when the server is created,
+// see NewServerOptions. Negative timeouts disable the timeout and negative MaxHeaderBytes
+// uses http.DefaultMaxHeaderBytes.
+type ServerOptions struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ErrorLog logs errors of accepting connections and unexpected behavior of handlers
+	ErrorLog *log.Logger
+	// ConnState is invoked when client connection changes state
+	ConnState func(net.Conn, http.ConnState)
+}
+
+// NewServerOptions creates server Options with defaults of given environment.
+//
+// Production environment limits duration of reading whole requests and writing
+// responses, development environment limits only reading of request headers,
+// so long running requests can be debugged.
+func NewServerOptions(env string) ServerOptions {
+	if env == productionEnv {
+		return ServerOptions{
+			ReadTimeout:       productionReadTimeout,
+			ReadHeaderTimeout: productionReadHeaderTimeout,
+			WriteTimeout:      productionWriteTimeout,
+			IdleTimeout:       productionIdleTimeout,
+			MaxHeaderBytes:    productionMaxHeaderBytes,
+		}
+	}
+	return ServerOptions{
+		ReadHeaderTimeout: defaultReadHeaderTimeout,
+		IdleTimeout:       defaultIdleTimeout,
+		MaxHeaderBytes:    defaultMaxHeaderBytes,
+	}
+}
+
+// serverOptions returns server Options with defaults of the environment
+// for timeouts and limits which are not set
+func (o Options) serverOptions() ServerOptions {
+	so := o.ServerOptions
+	env := NewServerOptions(o.Env)
+	so.ReadTimeout = durationOrDefault(so.ReadTimeout, env.ReadTimeout)
+	so.ReadHeaderTimeout = durationOrDefault(so.ReadHeaderTimeout, env.ReadHeaderTimeout)
+	so.WriteTimeout = durationOrDefault(so.WriteTimeout, env.WriteTimeout)
+	so.IdleTimeout = durationOrDefault(so.IdleTimeout, env.IdleTimeout)
+	switch {
+	case so.MaxHeaderBytes == 0:
+		so.MaxHeaderBytes = env.MaxHeaderBytes
+	case so.MaxHeaderBytes < 0:
+		so.MaxHeaderBytes = 0
+	}
+	return so
+}
+
+// durationOrDefault returns default duration when d is zero
+// and zero duration, which disables http server timeouts, when d is negative
+func durationOrDefault(d, def time.Duration) time.Duration {
+	switch {
+	case d == 0:
+		return def
+	case d < 0:
+		return 0
+	}
+	return d
+}
+
 // NewOptions creates New application Options instance
 func NewOptions() Options {
 	opts := Options{
@@ -50,11 +166,21 @@ func NewOptions() Options {
 			Body404:                default404Body,
 			Body405:                default405Body,
 		},
+		TLSOptions: TLSOptions{
+			TLSReloadInterval: defaultTLSReloadInterval,
+			TLSReloadSignals:  defaultTLSReloadSignals,
+		},
+
 		initialized: true,
 		Env:         defaultEnv,
 		Name:        defaultName,
 		Addr:        defaultAddr,
 		Version:     defaultVersion,
+		HookTimeout: defaultHookTimeout,
+
+		ShutdownTimeout: defaultShutdownTimeout,
+		StopTimeout:     defaultStopTimeout,
+		ShutdownSignals: defaultShutdownSignals,
 	}
 
 	return opts
@@ -0,0 +1,145 @@
+package flow
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-flow/flow/v2/di"
+)
+
+// BootstrapOption configures root module bootstrap, see Bootstrap
+type BootstrapOption func(m *Module)
+
+// override replaces providers of given type and name with constructor
+type override struct {
+	typ         reflect.Type
+	name        string
+	constructor interface{}
+	// replaced holds constructors of replaced providers,
+	// implementation is true when provider of an implementation of interface type was replaced
+	replaced       []string
+	implementation bool
+}
+
+// match records replaced provider creating instances of given type and returns true
+// when override replaces it. Interface overrides replace provider of an implementation
+// of the interface only when it is the only provider replaced by the override.
+func (o *override) match(c *di.Constructor) (bool, error) {
+	implementation := o.typ != c.Result && o.typ.Kind() == reflect.Interface && c.Result.Implements(o.typ)
+	if o.typ != c.Result && !implementation {
+		return false, nil
+	}
+
+	o.replaced = append(o.replaced, fmt.Sprintf("`%s` (%s)", c.Result, c.Name))
+	o.implementation = o.implementation || implementation
+	if o.implementation && len(o.replaced) > 1 {
+		return false, fmt.Errorf("override for %s matches more than one provider: %s", o, strings.Join(o.replaced, ", "))
+	}
+	return true, nil
+}
+
+func (o *override) String() string {
+	if o.name != "" {
+		return fmt.Sprintf("`%s` named `%s`", o.typ, o.name)
+	}
+	return fmt.Sprintf("`%s`", o.typ)
+}
+
+// Override replaces imported and exported providers creating instances of the same type
+// as target points to, anywhere in the module tree, with the provider using given constructor.
+// Target is a pointer to the replaced type, eg. Override(new(PaymentGateway), NewFakeGateway).
+// Interface override replaces provider creating an implementation of the interface,
+// eg. constructor returning *stripeGateway, when it is the only provider matched by the override.
+// Bootstrap fails when such override matches more than one provider.
+//
+// Providers are replaced before they are instantiated and bootstrap fails
+// when override does not replace any provider.
+func Override(target interface{}, constructor interface{}) BootstrapOption {
+	return OverrideNamed("", target, constructor)
+}
+
+// OverrideNamed replaces named providers, see Override and NewNamedProvider
+func OverrideNamed(name string, target interface{}, constructor interface{}) BootstrapOption {
+	return func(m *Module) {
+		o := &override{
+			name:        name,
+			constructor: constructor,
+		}
+		if typ := reflect.TypeOf(target); typ != nil && typ.Kind() == reflect.Ptr {
+			o.typ = typ.Elem()
+		}
+		m.overrides = append(m.overrides, o)
+	}
+}
+
+// validateOverrides checks overrides before providers are instantiated
+func (m *Module) validateOverrides() error {
+	for _, o := range m.overrides {
+		if o.typ == nil {
+			return fmt.Errorf("override target has to be a pointer to the replaced type")
+		}
+		c, err := di.Inspect(o.constructor)
+		if err != nil {
+			return fmt.Errorf("invalid override for %s. Error: %w", o, err)
+		}
+		if !c.Result.AssignableTo(o.typ) {
+			return fmt.Errorf("invalid override for %s. Error: constructor %s creates `%s`", o, c.Name, c.Result)
+		}
+	}
+	return nil
+}
+
+// unusedOverrides returns error describing overrides which did not replace any provider
+func (m *Module) unusedOverrides() error {
+	var unused []string
+	for _, o := range m.overrides {
+		if len(o.replaced) == 0 {
+			unused = append(unused, o.String())
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	return fmt.Errorf("overrides did not replace any provider: %s", strings.Join(unused, ", "))
+}
+
+// overrideProvider returns provider replacing the provider with given constructor
+// registered under given name, or nil when the provider is not overridden
+func (m *Module) overrideProvider(p Provider, c *di.Constructor, name string) (Provider, error) {
+	for _, o := range m.root().overrides {
+		if o.name != name {
+			continue
+		}
+		ok, err := o.match(c)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		if name != "" {
+			return NewNamedProvider(name, o.constructor), nil
+		}
+		if gp, ok := p.(GlobalProvider); ok && gp.Global() {
+			return NewGlobalProvider(o.constructor), nil
+		}
+
+		// overriding provider creates instances in the same scope
+		scope := ""
+		if sp, ok := p.(scopedProvider); ok {
+			scope = sp.scope()
+		}
+		switch scope {
+		case ProviderScopeRequest:
+			return NewRequestProvider(o.constructor), nil
+		case ProviderScopeLazy:
+			return NewLazyProvider(o.constructor), nil
+		case ProviderScopeFactory:
+			return NewFactoryProvider(o.constructor), nil
+		}
+		return NewProvider(o.constructor), nil
+	}
+	return nil, nil
+}
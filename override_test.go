@@ -0,0 +1,139 @@
+package flow
+
+import (
+	"strings"
+	"testing"
+)
+
+type paymentGateway interface {
+	Charge(amount int) string
+}
+
+type stripeGateway struct{}
+
+func (stripeGateway) Charge(amount int) string { return "stripe" }
+
+type fakeGateway struct{}
+
+func (fakeGateway) Charge(amount int) string { return "fake" }
+
+type checkout struct {
+	gateway paymentGateway
+}
+
+func TestBootstrapOverride(t *testing.T) {
+	var co *checkout
+	stripeCalled := false
+
+	m, err := Bootstrap(&testModule{
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func() paymentGateway {
+							stripeCalled = true
+							return &stripeGateway{}
+						}),
+						NewProvider(func(g paymentGateway) *checkout {
+							co = &checkout{gateway: g}
+							return co
+						}),
+					},
+				}}
+			}),
+		},
+	}, Override(new(paymentGateway), func() *fakeGateway { return &fakeGateway{} }))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stripeCalled {
+		t.Error("overridden constructor should not be invoked")
+	}
+	if got := co.gateway.Charge(1); got != "fake" {
+		t.Errorf("expected fake gateway, got %s", got)
+	}
+	if p := m.Graph().Modules[1].Providers[0]; !p.Overridden || p.Type != "*flow.fakeGateway" {
+		t.Errorf("wrong overridden provider in graph: %+v", p)
+	}
+}
+
+func TestBootstrapOverrideImplementation(t *testing.T) {
+	var co *checkout
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *stripeGateway { return &stripeGateway{} }),
+			NewProvider(func(g paymentGateway) *checkout {
+				co = &checkout{gateway: g}
+				return co
+			}),
+		},
+	}, Override(new(paymentGateway), func() *fakeGateway { return &fakeGateway{} }))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := co.gateway.Charge(1); got != "fake" {
+		t.Errorf("expected fake gateway, got %s", got)
+	}
+}
+
+type cardGateway struct{}
+
+func (cardGateway) Charge(amount int) string { return "card" }
+
+func TestBootstrapOverrideAmbiguous(t *testing.T) {
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *stripeGateway { return &stripeGateway{} }),
+			NewProvider(func() *cardGateway { return &cardGateway{} }),
+		},
+	}, Override(new(paymentGateway), func() *fakeGateway { return &fakeGateway{} }))
+
+	if err == nil || !strings.Contains(err.Error(), "override for `flow.paymentGateway` matches more than one provider") {
+		t.Errorf("expected ambiguous override error, got: %v", err)
+	}
+}
+
+func TestBootstrapOverrideNamed(t *testing.T) {
+	var replica *testDB
+
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB { return &testDB{dsn: "primary"} }),
+			NewNamedProvider("replica", func() *testDB { return &testDB{dsn: "replica"} }),
+			NewProvider(func(p testDBParams) *testRepo {
+				replica = p.Replica
+				return &testRepo{db: p.Primary}
+			}),
+		},
+	}, OverrideNamed("replica", new(*testDB), func() *testDB { return &testDB{dsn: "fake"} }))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if replica.dsn != "fake" {
+		t.Errorf("expected overridden replica, got %s", replica.dsn)
+	}
+}
+
+func TestBootstrapUnusedOverride(t *testing.T) {
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB { return &testDB{} }),
+		},
+	}, Override(new(paymentGateway), func() *fakeGateway { return &fakeGateway{} }))
+
+	if err == nil || !strings.Contains(err.Error(), "overrides did not replace any provider: `flow.paymentGateway`") {
+		t.Fatalf("expected unused override error, got: %v", err)
+	}
+}
+
+func TestBootstrapInvalidOverride(t *testing.T) {
+	_, err := Bootstrap(&testModule{}, Override(new(paymentGateway), func() *testDB { return &testDB{} }))
+
+	if err == nil || !strings.Contains(err.Error(), "creates `*flow.testDB`") {
+		t.Fatalf("expected invalid override error, got: %v", err)
+	}
+}
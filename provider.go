@@ -1,5 +1,13 @@
 package flow
 
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/go-flow/flow/v2/di"
+)
+
 type Provider interface {
 	Provide(injector Injector) (interface{}, error)
 }
@@ -12,8 +20,339 @@ func (ip *instanceProvider) Provide(injector Injector) (interface{}, error) {
 	return injector.Provide(ip.constructor)
 }
 
+// Constructor returns constructor function used to create instance
+func (ip *instanceProvider) Constructor() interface{} {
+	return ip.constructor
+}
+
+// NewProvider creates provider which creates instance using given constructor function.
+//
+// Constructor parameters are injected from module container and constructor
+// has to return the instance and optionally an error, eg. func(...) T,
+// func(...) (T, error) or func(...) (T, func(), error) where returned
+// function is invoked during module cleanup.
 func NewProvider(constructor interface{}) Provider {
 	return &instanceProvider{
 		constructor: constructor,
 	}
 }
+
+// NamedProvider interface is implemented by providers
+// which register provided instances under a name.
+//
+// Named instances are injected only to struct fields and fields of
+// constructor parameter structs (see di.In) tagged with `inject:"name"`.
+type NamedProvider interface {
+	Provider
+	Name() string
+}
+
+type namedProvider struct {
+	instanceProvider
+	name string
+}
+
+func (np *namedProvider) Name() string {
+	return np.name
+}
+
+// NewNamedProvider creates provider which registers instance
+// created by constructor under given name
+func NewNamedProvider(name string, constructor interface{}) NamedProvider {
+	return &namedProvider{
+		instanceProvider: instanceProvider{
+			constructor: constructor,
+		},
+		name: name,
+	}
+}
+
+// GlobalProvider interface is implemented by providers which register provided
+// instance to containers of all modules, regardless of the module providing it.
+//
+// Global providers are useful in strict mode, see StrictExports, for
+// instances such as configuration or logger used by every module.
+type GlobalProvider interface {
+	Provider
+	Global() bool
+}
+
+type globalProvider struct {
+	instanceProvider
+}
+
+func (gp *globalProvider) Global() bool {
+	return true
+}
+
+// NewGlobalProvider creates provider which registers instance
+// created by constructor to containers of all modules
+func NewGlobalProvider(constructor interface{}) GlobalProvider {
+	return &globalProvider{
+		instanceProvider: instanceProvider{
+			constructor: constructor,
+		},
+	}
+}
+
+type requestProvider struct {
+	constructor interface{}
+}
+
+func (rp *requestProvider) Provide(injector Injector) (interface{}, error) {
+	return di.NewRequestScoped(rp.constructor)
+}
+
+// Constructor returns constructor function used to create request scoped instances
+func (rp *requestProvider) Constructor() interface{} {
+	return rp.constructor
+}
+
+func (rp *requestProvider) scope() string {
+	return ProviderScopeRequest
+}
+
+// NewRequestProvider creates provider of request scoped instances.
+//
+// Constructor is invoked lazily, at most once per http request, when an action handler
+// depends on the instance created by it. Constructor parameters are injected
+// from module container, from other request scoped providers and *http.Request
+// of the current request.
+//
+// Request scoped instances are injected to exported action handler fields.
+// Action handlers with such fields are copied for every request, so the
+// handler registered at bootstrap is never modified.
+func NewRequestProvider(constructor interface{}) Provider {
+	return &requestProvider{
+		constructor: constructor,
+	}
+}
+
+// GroupProvider interface is implemented by providers which add created instance
+// to a group of dependencies instead of registering it by type.
+//
+// Group instances are injected as a slice using `group:"name"` struct tag,
+// see di.Container.AddGroup.
+type GroupProvider interface {
+	Provider
+	Group() string
+}
+
+type groupProvider struct {
+	instanceProvider
+	group string
+}
+
+func (gp *groupProvider) Group() string {
+	return gp.group
+}
+
+// NewGroupProvider creates provider which adds instance created by constructor
+// to given group. Modules contribute to the group of parent module by exporting group providers.
+func NewGroupProvider(group string, constructor interface{}) GroupProvider {
+	return &groupProvider{
+		instanceProvider: instanceProvider{
+			constructor: constructor,
+		},
+		group: group,
+	}
+}
+
+type lazyProvider struct {
+	constructor interface{}
+}
+
+func (lp *lazyProvider) Provide(injector Injector) (interface{}, error) {
+	return di.NewLazy(lp.constructor)
+}
+
+// Constructor returns constructor function used to create instance
+func (lp *lazyProvider) Constructor() interface{} {
+	return lp.constructor
+}
+
+func (lp *lazyProvider) scope() string {
+	return ProviderScopeLazy
+}
+
+// NewLazyProvider creates provider which creates instance using given constructor
+// on the first injection of the instance, instead of during module bootstrap.
+// Created instance is reused afterwards and cleanup function returned
+// by constructor is invoked during module cleanup. Lifecycle hooks of the instance
+// are registered when it is created, so OnInit of instance created after Module.Init is not invoked.
+func NewLazyProvider(constructor interface{}) Provider {
+	return &lazyProvider{
+		constructor: constructor,
+	}
+}
+
+type factoryProvider struct {
+	constructor interface{}
+}
+
+func (fp *factoryProvider) Provide(injector Injector) (interface{}, error) {
+	return di.NewFactory(fp.constructor)
+}
+
+// Constructor returns constructor function used to create instances
+func (fp *factoryProvider) Constructor() interface{} {
+	return fp.constructor
+}
+
+func (fp *factoryProvider) scope() string {
+	return ProviderScopeFactory
+}
+
+// NewFactoryProvider creates provider which creates new instance using given constructor
+// on every injection of the instance. Constructor has to return the instance
+// and optionally an error, eg. func(...) T or func(...) (T, error).
+func NewFactoryProvider(constructor interface{}) Provider {
+	return &factoryProvider{
+		constructor: constructor,
+	}
+}
+
+type valueProvider struct {
+	value    interface{}
+	location string
+}
+
+func (vp *valueProvider) Provide(injector Injector) (interface{}, error) {
+	if vp.value == nil {
+		return nil, fmt.Errorf("value provider (%s) can not provide nil value", vp.location)
+	}
+	return vp.value, nil
+}
+
+func (vp *valueProvider) inspect() (*di.Constructor, error) {
+	if vp.value == nil {
+		return nil, fmt.Errorf("value provider (%s) can not provide nil value", vp.location)
+	}
+	return &di.Constructor{
+		Name:     "value",
+		Location: vp.location,
+		Result:   reflect.TypeOf(vp.value),
+	}, nil
+}
+
+// NewValueProvider creates provider of already created instance, eg. configuration struct
+func NewValueProvider(value interface{}) Provider {
+	return &valueProvider{
+		value:    value,
+		location: callerLocation(),
+	}
+}
+
+type aliasProvider struct {
+	typ      reflect.Type
+	target   reflect.Type
+	location string
+}
+
+func (ap *aliasProvider) Provide(injector Injector) (interface{}, error) {
+	return di.NewAlias(ap.typ, ap.target)
+}
+
+func (ap *aliasProvider) inspect() (*di.Constructor, error) {
+	if _, err := di.NewAlias(ap.typ, ap.target); err != nil {
+		return nil, fmt.Errorf("invalid alias provider (%s). Error: %w", ap.location, err)
+	}
+	return &di.Constructor{
+		Name:         "alias",
+		Location:     ap.location,
+		Result:       ap.typ,
+		Dependencies: []di.Dependency{{Type: ap.target}},
+	}, nil
+}
+
+// NewAliasProvider creates provider which binds the type "alias" points to,
+// to the instance of the type "target" points to, which is already registered,
+// eg. NewAliasProvider(new(Repository), new(*SQLRepository)).
+//
+// Alias of an interface takes precedence over other implementations of the interface.
+func NewAliasProvider(alias interface{}, target interface{}) Provider {
+	return &aliasProvider{
+		typ:      elemType(alias),
+		target:   elemType(target),
+		location: callerLocation(),
+	}
+}
+
+type existingProvider struct {
+	typ      reflect.Type
+	location string
+}
+
+func (ep *existingProvider) Provide(injector Injector) (interface{}, error) {
+	// identity constructor resolves existing instance from the injector
+	fn := reflect.MakeFunc(reflect.FuncOf([]reflect.Type{ep.typ}, []reflect.Type{ep.typ}, false), func(in []reflect.Value) []reflect.Value {
+		return in
+	})
+	return injector.Provide(fn.Interface())
+}
+
+func (ep *existingProvider) inspect() (*di.Constructor, error) {
+	if ep.typ == nil {
+		return nil, fmt.Errorf("invalid existing provider (%s). Error: target has to be a pointer to the provided type", ep.location)
+	}
+	return &di.Constructor{
+		Name:         "existing",
+		Location:     ep.location,
+		Result:       ep.typ,
+		Dependencies: []di.Dependency{{Type: ep.typ}},
+	}, nil
+}
+
+// NewExistingProvider creates provider of the instance already registered
+// to module container, eg. to export instance imported by the module
+// to the parent module with NewExistingProvider(new(*sql.DB)).
+func NewExistingProvider(target interface{}) Provider {
+	return &existingProvider{
+		typ:      elemType(target),
+		location: callerLocation(),
+	}
+}
+
+// elemType returns the type "ptr" points to, or nil if "ptr" is not a pointer
+func elemType(ptr interface{}) reflect.Type {
+	typ := reflect.TypeOf(ptr)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return nil
+	}
+	return typ.Elem()
+}
+
+// callerLocation returns location of the provider constructor caller in `file:line` format
+func callerLocation() string {
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+type decoratorProvider struct {
+	decorator interface{}
+}
+
+func (dp *decoratorProvider) Provide(injector Injector) (interface{}, error) {
+	return di.NewDecorator(dp.decorator)
+}
+
+// Constructor returns decorator function
+func (dp *decoratorProvider) Constructor() interface{} {
+	return dp.decorator
+}
+
+// NewDecoratorProvider creates provider which decorates instances of the type returned by
+// decorator function, eg. func(inner Repo, m *Metrics) Repo, see di.Decorator.
+//
+// Decorator is applied to instances injected afterwards by the same type within the module,
+// exported decorators are applied within the parent module as well.
+// Instances are decorated before providers of the same batch receive them and
+// decorators registered by several modules are applied in order of their registration.
+func NewDecoratorProvider(decorator interface{}) Provider {
+	return &decoratorProvider{
+		decorator: decorator,
+	}
+}
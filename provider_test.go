@@ -0,0 +1,180 @@
+package flow
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProviderKinds(t *testing.T) {
+	cfg := &testConfig{DSN: "db"}
+	lazyCalls, factoryCalls := 0, 0
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewAliasProvider(new(paymentGateway), new(*fakeGateway)),
+			NewProvider(func() *stripeGateway { return &stripeGateway{} }),
+			NewProvider(func() *fakeGateway { return &fakeGateway{} }),
+			NewLazyProvider(func(cfg *testConfig) *testDB {
+				lazyCalls++
+				return &testDB{dsn: cfg.DSN}
+			}),
+			NewFactoryProvider(func(db *testDB) *testRepo {
+				factoryCalls++
+				return &testRepo{db: db}
+			}),
+			NewValueProvider(cfg),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					exports: []Provider{
+						NewExistingProvider(new(*testConfig)),
+					},
+				}}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lazyCalls != 0 || factoryCalls != 0 {
+		t.Fatalf("on demand constructors should not be invoked during bootstrap: %d, %d", lazyCalls, factoryCalls)
+	}
+
+	err = m.Invoke(func(g paymentGateway, a, b *testRepo, c *testConfig) {
+		if g.Charge(1) != "fake" {
+			t.Errorf("alias is not resolved to target instance")
+		}
+		if a == b || a.db != b.db || a.db.dsn != "db" {
+			t.Errorf("wrong factory instances: %+v, %+v", a, b)
+		}
+		if c != cfg {
+			t.Errorf("wrong value instance: %+v", c)
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lazyCalls != 1 || factoryCalls != 2 {
+		t.Errorf("wrong number of constructor calls: lazy %d, factory %d", lazyCalls, factoryCalls)
+	}
+
+	providers := m.Graph().Modules[0].Providers
+	scopes := map[string]string{}
+	for _, p := range providers {
+		scopes[p.Type] = p.Scope
+	}
+	if scopes["*flow.testDB"] != ProviderScopeLazy || scopes["*flow.testRepo"] != ProviderScopeFactory {
+		t.Errorf("wrong provider scopes in graph: %v", scopes)
+	}
+}
+
+func TestProviderKindsErrors(t *testing.T) {
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewAliasProvider(new(paymentGateway), new(*testDB)),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid alias provider") {
+		t.Errorf("expected invalid alias error, got: %v", err)
+	}
+
+	_, err = Bootstrap(&testModule{
+		imports: []Provider{
+			NewExistingProvider(new(*testDB)),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing dependency `*flow.testDB` for constructor existing") {
+		t.Errorf("expected missing dependency error, got: %v", err)
+	}
+}
+
+type meteredGateway struct {
+	inner paymentGateway
+}
+
+func (g *meteredGateway) Charge(amount int) string { return "metered " + g.inner.Charge(amount) }
+
+type retryGateway struct {
+	inner paymentGateway
+}
+
+func (g *retryGateway) Charge(amount int) string { return "retry " + g.inner.Charge(amount) }
+
+func TestDecoratorProvider(t *testing.T) {
+	var co *checkout
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func(g paymentGateway) *checkout {
+				co = &checkout{gateway: g}
+				return co
+			}),
+			NewDecoratorProvider(func(inner paymentGateway, cfg *testConfig) paymentGateway {
+				return &meteredGateway{inner: inner}
+			}),
+			NewProvider(func() paymentGateway { return &stripeGateway{} }),
+			NewValueProvider(&testConfig{}),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					exports: []Provider{
+						NewDecoratorProvider(func(inner paymentGateway) paymentGateway {
+							return &retryGateway{inner: inner}
+						}),
+					},
+				}}
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := co.gateway.Charge(1); got != "metered stripe" {
+		t.Errorf("consumer received undecorated instance: %s", got)
+	}
+
+	err = m.Invoke(func(g paymentGateway) {
+		if got := g.Charge(1); got != "retry metered stripe" {
+			t.Errorf("wrong decorators order: %s", got)
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+type auditLog interface {
+	Write(msg string) string
+}
+
+type fileAudit struct{}
+
+func (fileAudit) Write(msg string) string { return msg }
+
+func TestDecoratorProviderCycle(t *testing.T) {
+	done := make(chan error, 1)
+	go func() {
+		_, err := Bootstrap(&testModule{
+			imports: []Provider{
+				NewProvider(func() paymentGateway { return &stripeGateway{} }),
+				NewProvider(func() auditLog { return fileAudit{} }),
+				NewDecoratorProvider(func(inner paymentGateway, a auditLog) paymentGateway { return inner }),
+				NewDecoratorProvider(func(inner auditLog, g paymentGateway) auditLog { return inner }),
+			},
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "dependency cycle detected") {
+			t.Errorf("expected dependency cycle error, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("bootstrap is blocked by decorators depending on each other")
+	}
+}
@@ -3,6 +3,7 @@ package flow
 import (
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/go-flow/flow/v2/response"
 )
@@ -27,6 +28,35 @@ func ResponseHeader(code int, headers map[string]string) Response {
 	return response.NewHeaders(code, headers)
 }
 
+// ResponseCreated creates 201 Created Response with Location header pointing
+// to the created resource and JSON rendered data. Response has no body when data is nil.
+func ResponseCreated(location string, data interface{}) Response {
+	return response.NewCreated(location, data)
+}
+
+// ResponseAccepted creates 202 Accepted Response with Location header pointing
+// to the status monitor of the accepted request and JSON rendered data.
+// Response has no body when data is nil.
+func ResponseAccepted(location string, data interface{}) Response {
+	return response.NewAccepted(location, data)
+}
+
+// ResponseNoContent creates 204 No Content Response
+func ResponseNoContent() Response {
+	return response.NewNoContent()
+}
+
+// ResponseNotModified creates 304 Not Modified Response with given ETag header
+func ResponseNotModified(etag string) Response {
+	return response.NewNotModified(etag)
+}
+
+// ResponseTooManyRequests creates 429 Too Many Requests Error Response
+// with Retry-After header for given duration
+func ResponseTooManyRequests(retryAfter time.Duration, err error) Response {
+	return response.NewTooManyRequests(retryAfter, err)
+}
+
 // ResponseFile serves content from given file
 func ResponseFile(filepath string) Response {
 	return response.NewFile(filepath)
@@ -40,6 +70,9 @@ func ResponseFile(filepath string) Response {
 //
 // Content-Type is set using mime#TypeByExtension with the filename's extension. Content-Type will default to
 // application/octet-stream if using a filename with an unknown extension.
+//
+// When reader implements io.ReadSeeker (eg. *os.File), Range, If-Range and conditional requests are supported.
+// Use response.NewDownload to set ETag and modification time of the content.
 func ResponseDownload(name string, reader io.Reader) Response {
 	return response.NewDownload(name, reader)
 }
@@ -64,7 +97,30 @@ func ResponseData(code int, data []byte, contentType []string) Response {
 	return response.NewData(code, data, contentType)
 }
 
-// ResponseReader creates io.Reader render Response for given http code, reader and content type
+// ResponseReader creates io.Reader Response for given http code, reader and content type.
+//
+// When reader implements io.ReadSeeker and code is 200, Range, If-Range and conditional requests are supported.
+// Use response.NewContentReader to set ETag and modification time of the content.
 func ResponseReader(code int, reader io.Reader, contentType []string) Response {
-	return response.NewReader(code, reader, contentType)
+	return response.NewContentReader(code, reader, contentType)
+}
+
+// WithHeaders decorates any Response with given headers.
+// Headers are applied just before the Response writes the status and
+// replace values with the same key set by the Response.
+func WithHeaders(resp Response, headers http.Header) Response {
+	return response.WithHeaders(resp, headers)
+}
+
+// WithCookies decorates any Response with given cookies.
+// Cookies are set just before the Response writes the status.
+func WithCookies(resp Response, cookies ...*http.Cookie) Response {
+	return response.WithCookies(resp, cookies...)
+}
+
+// WithStatus decorates any Response with given status code.
+// Status code is replaced only when the Response writes its own Status(),
+// so status codes of conditional or partial responses (eg. 304, 206) are kept.
+func WithStatus(resp Response, code int) Response {
+	return response.WithStatus(resp, code)
 }
@@ -0,0 +1,178 @@
+package response
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// content holds the data shared by Responses that stream
+// io.Reader content to the client
+type content struct {
+	name    string
+	reader  io.Reader
+	modtime time.Time
+	etag    string
+}
+
+// serve writes the content to the response writer.
+//
+// When the reader implements io.ReadSeeker and the status code is 200,
+// content is served with http.ServeContent, which handles Range, If-Range,
+// If-Match, If-None-Match, If-Modified-Since and If-Unmodified-Since requests
+// and sets an accurate Content-Length header.
+// Otherwise Content-Length is set only when the size of the reader is known
+// and conditional requests are answered using ETag and Last-Modified values.
+func (c *content) serve(w http.ResponseWriter, r *http.Request, code int) error {
+	h := w.Header()
+	if c.etag != "" {
+		h.Set("ETag", c.etag)
+	}
+
+	if rs, ok := c.reader.(io.ReadSeeker); ok && code == http.StatusOK {
+		http.ServeContent(w, r, c.name, c.modtime, rs)
+		return nil
+	}
+
+	if !isZeroTime(c.modtime) {
+		h.Set("Last-Modified", c.modtime.UTC().Format(http.TimeFormat))
+	}
+
+	if code == http.StatusOK && c.notModified(r) {
+		delete(h, "Content-Type")
+		delete(h, "Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	if size, ok := readerSize(c.reader); ok {
+		h.Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+
+	w.WriteHeader(code)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	_, err := io.Copy(w, c.reader)
+	return err
+}
+
+// notModified reports whether conditional GET or HEAD request
+// can be answered with 304 Not Modified
+func (c *content) notModified(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if c.etag == "" {
+			return false
+		}
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || weakETag(tag) == weakETag(c.etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || isZeroTime(c.modtime) {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	// Last-Modified has one second precision
+	return !c.modtime.Truncate(time.Second).After(t)
+}
+
+// weakETag strips weak validator prefix from etag,
+// as If-None-Match uses weak comparison
+func weakETag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+func isZeroTime(t time.Time) bool {
+	return t.IsZero() || t.Equal(time.Unix(0, 0))
+}
+
+// readerSize returns the number of unread bytes of well known readers
+func readerSize(reader io.Reader) (int64, bool) {
+	switch v := reader.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case io.Seeker:
+		cur, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err := v.Seek(cur, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - cur, true
+	}
+	return 0, false
+}
+
+// contentDisposition formats Content-Disposition header value for given
+// disposition type and file name as defined by RFC 6266.
+//
+// filename parameter holds ASCII fallback of the name, while filename*
+// parameter holds UTF-8 percent encoded name as defined by RFC 5987.
+func contentDisposition(dispType, name string) string {
+	if name == "" {
+		return dispType
+	}
+
+	var fallback strings.Builder
+	for _, r := range name {
+		switch {
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		case r < ' ' || r > '~':
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+
+	return dispType + `; filename="` + fallback.String() + `"; filename*=UTF-8''` + encodeRFC5987(name)
+}
+
+const upperhex = "0123456789ABCDEF"
+
+// encodeRFC5987 percent encodes all bytes of s which are not attr-char
+func encodeRFC5987(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(upperhex[c>>4])
+		b.WriteByte(upperhex[c&15])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,147 @@
+package response
+
+import "net/http"
+
+// Responder defines interface implemented by all Responses.
+// It is equivalent to flow.Response interface.
+type Responder interface {
+	Status() int
+	Handle(w http.ResponseWriter, r *http.Request) error
+}
+
+// Decorator wraps any Responder and applies status code,
+// headers and cookies just before the wrapped Responder writes the status.
+// Values set by Decorator take precedence over values set by wrapped Responder.
+type Decorator struct {
+	inner   Responder
+	code    int
+	header  http.Header
+	cookies []*http.Cookie
+	cleanup func()
+}
+
+// WithHeaders decorates Responder with given headers.
+// Header values replace values with the same key set by the Responder.
+func WithHeaders(res Responder, header http.Header) *Decorator {
+	return &Decorator{
+		inner:  res,
+		header: header,
+	}
+}
+
+// WithCookies decorates Responder with given cookies
+func WithCookies(res Responder, cookies ...*http.Cookie) *Decorator {
+	return &Decorator{
+		inner:   res,
+		cookies: cookies,
+	}
+}
+
+// WithStatus decorates Responder with given status code.
+// Status code is replaced only when the Responder writes its own Status(),
+// so status codes of conditional or partial responses (eg. 304, 206) are kept.
+func WithStatus(res Responder, code int) *Decorator {
+	return &Decorator{
+		inner: res,
+		code:  code,
+	}
+}
+
+// WithCleanup decorates Responder with function invoked after the Responder is handled,
+// eg. to release resources used by Responders which stream data while they are handled
+func WithCleanup(res Responder, cleanup func()) *Decorator {
+	return &Decorator{
+		inner:   res,
+		cleanup: cleanup,
+	}
+}
+
+// Unwrap returns decorated Responder
+func (d *Decorator) Unwrap() Responder {
+	return d.inner
+}
+
+func (d *Decorator) Status() int {
+	if d.code != 0 {
+		return d.code
+	}
+	return d.inner.Status()
+}
+
+func (d *Decorator) Handle(w http.ResponseWriter, r *http.Request) error {
+	if d.cleanup != nil {
+		defer d.cleanup()
+	}
+
+	dw := &decoratorWriter{
+		ResponseWriter: w,
+		decorator:      d,
+	}
+
+	if err := d.inner.Handle(dw, r); err != nil {
+		return err
+	}
+
+	// ensure decorations are applied for Responders which do not write anything
+	if !dw.wroteHeader && d.decorates() {
+		dw.WriteHeader(d.inner.Status())
+	}
+	return nil
+}
+
+// decorates returns true when Decorator changes status code, headers or cookies
+func (d *Decorator) decorates() bool {
+	return d.code != 0 || len(d.header) > 0 || len(d.cookies) > 0
+}
+
+func (d *Decorator) apply(w http.ResponseWriter) {
+	h := w.Header()
+	for k, v := range d.header {
+		h[http.CanonicalHeaderKey(k)] = v
+	}
+	for _, c := range d.cookies {
+		http.SetCookie(w, c)
+	}
+}
+
+// decoratorWriter applies Decorator changes when the status is written
+type decoratorWriter struct {
+	http.ResponseWriter
+	decorator   *Decorator
+	wroteHeader bool
+}
+
+func (dw *decoratorWriter) WriteHeader(code int) {
+	if dw.wroteHeader {
+		return
+	}
+	dw.wroteHeader = true
+
+	dw.decorator.apply(dw.ResponseWriter)
+	if dw.decorator.code != 0 && code == dw.decorator.inner.Status() {
+		code = dw.decorator.code
+	}
+	dw.ResponseWriter.WriteHeader(code)
+}
+
+func (dw *decoratorWriter) Write(b []byte) (int, error) {
+	if !dw.wroteHeader {
+		dw.WriteHeader(http.StatusOK)
+	}
+	return dw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher interface
+func (dw *decoratorWriter) Flush() {
+	if !dw.wroteHeader {
+		dw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := dw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns wrapped http.ResponseWriter
+func (dw *decoratorWriter) Unwrap() http.ResponseWriter {
+	return dw.ResponseWriter
+}
@@ -0,0 +1,116 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecoratorJSON(t *testing.T) {
+	res := WithCookies(
+		WithHeaders(
+			WithStatus(NewJSON(http.StatusOK, map[string]string{"a": "b"}), http.StatusCreated),
+			http.Header{"X-Request-Id": {"42"}, "content-type": {"application/vnd.api+json"}},
+		),
+		&http.Cookie{Name: "session", Value: "abc"},
+	)
+
+	if res.Status() != http.StatusCreated {
+		t.Errorf("wrong Status(): want %d, got %d", http.StatusCreated, res.Status())
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("wrong status code: want %d, got %d", http.StatusCreated, w.Code)
+	}
+	if got := w.Header().Get("X-Request-Id"); got != "42" {
+		t.Errorf("wrong X-Request-Id header: got %q", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/vnd.api+json" {
+		t.Errorf("wrong Content-Type header: got %q", got)
+	}
+	if got := w.Header().Get("Set-Cookie"); got != "session=abc" {
+		t.Errorf("wrong Set-Cookie header: got %q", got)
+	}
+	if got := w.Body.String(); got != `{"a":"b"}` {
+		t.Errorf("wrong body: got %q", got)
+	}
+}
+
+func TestDecoratorRedirect(t *testing.T) {
+	res := WithCookies(NewRedirect(http.StatusFound, "/login"), &http.Cookie{Name: "flash", Value: "bye"})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/logout", nil)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if w.Code != http.StatusFound {
+		t.Errorf("wrong status code: want %d, got %d", http.StatusFound, w.Code)
+	}
+	if got := w.Header().Get("Location"); got != "/login" {
+		t.Errorf("wrong Location header: got %q", got)
+	}
+	if got := w.Header().Get("Set-Cookie"); got != "flash=bye" {
+		t.Errorf("wrong Set-Cookie header: got %q", got)
+	}
+}
+
+func TestDecoratorKeepsConditionalStatus(t *testing.T) {
+	res := WithStatus(NewDownload("a.txt", strings.NewReader("abc")).WithETag(`"v1"`), http.StatusAccepted)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"v1"`)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusNotModified {
+		t.Errorf("wrong status code: want %d, got %d", http.StatusNotModified, w.Code)
+	}
+}
+
+type emptyResponse struct{}
+
+func (emptyResponse) Status() int { return http.StatusNoContent }
+
+func (emptyResponse) Handle(w http.ResponseWriter, r *http.Request) error { return nil }
+
+func TestDecoratorEmptyResponse(t *testing.T) {
+	res := WithHeaders(emptyResponse{}, http.Header{"X-Empty": {"yes"}})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusNoContent {
+		t.Errorf("wrong status code: want %d, got %d", http.StatusNoContent, w.Code)
+	}
+	if got := w.Header().Get("X-Empty"); got != "yes" {
+		t.Errorf("wrong X-Empty header: got %q", got)
+	}
+}
+
+func TestDecoratorCleanup(t *testing.T) {
+	cleaned := false
+	res := WithCleanup(NewText(http.StatusOK, "body"), func() { cleaned = true })
+
+	w := httptest.NewRecorder()
+	if err := res.Handle(w, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cleaned {
+		t.Error("expected cleanup after the Responder is handled")
+	}
+	if w.Body.String() != "body" {
+		t.Errorf("wrong body: %q", w.Body.String())
+	}
+}
@@ -1,16 +1,15 @@
 package response
 
 import (
-	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"path/filepath"
+	"time"
 )
 
 type Download struct {
-	name   string
-	reader io.Reader
+	content
 }
 
 // NewDownload creates file attachment Response with following headers:
@@ -21,13 +20,31 @@ type Download struct {
 //
 // Content-Type is set using mime#TypeByExtension with the filename's extension. Content-Type will default to
 // application/octet-stream if using a filename with an unknown extension.
+//
+// When reader implements io.ReadSeeker, Range and conditional requests are supported.
 func NewDownload(name string, reader io.Reader) *Download {
 	return &Download{
-		name:   name,
-		reader: reader,
+		content: content{
+			name:   name,
+			reader: reader,
+		},
 	}
 }
 
+// WithModTime sets modification time used for Last-Modified header
+// and If-Modified-Since, If-Unmodified-Since and If-Range request headers
+func (rd *Download) WithModTime(modtime time.Time) *Download {
+	rd.modtime = modtime
+	return rd
+}
+
+// WithETag sets ETag header used for If-Match, If-None-Match
+// and If-Range request headers. Value has to be quoted, eg. `"v1"` or `W/"v1"`
+func (rd *Download) WithETag(etag string) *Download {
+	rd.etag = etag
+	return rd
+}
+
 func (Download) Status() int {
 	return http.StatusOK
 }
@@ -39,11 +56,15 @@ func (rd *Download) Handle(w http.ResponseWriter, r *http.Request) error {
 		t = "application/octet-stream"
 	}
 
-	cd := fmt.Sprintf("attachment; filename=%s", rd.name)
+	// filename parameter is omitted for unnamed downloads, Base of empty path is "."
+	name := ""
+	if rd.name != "" {
+		name = filepath.Base(rd.name)
+	}
+
 	h := w.Header()
-	h.Add("Content-Disposition", cd)
-	h.Add("Content-Type", t)
+	h.Set("Content-Disposition", contentDisposition("attachment", name))
+	h.Set("Content-Type", t)
 
-	_, err := io.Copy(w, rd.reader)
-	return err
+	return rd.serve(w, r, http.StatusOK)
 }
@@ -0,0 +1,161 @@
+package response
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type onlyReader struct {
+	r *strings.Reader
+}
+
+func (o onlyReader) Read(p []byte) (int, error) {
+	return o.r.Read(p)
+}
+
+func TestDownloadHeaders(t *testing.T) {
+	res := NewDownload("report ü.pdf", bytes.NewReader([]byte("0123456789")))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code: want %d, got %d", http.StatusOK, w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Errorf("wrong Content-Type: got %q", got)
+	}
+	if got := w.Header().Get("Content-Length"); got != "10" {
+		t.Errorf("wrong Content-Length: got %q", got)
+	}
+	want := `attachment; filename="report _.pdf"; filename*=UTF-8''report%20%C3%BC.pdf`
+	if got := w.Header().Get("Content-Disposition"); got != want {
+		t.Errorf("wrong Content-Disposition:\nwant %s\n got %s", want, got)
+	}
+}
+
+func TestDownloadUnnamed(t *testing.T) {
+	res := NewDownload("", bytes.NewReader([]byte("0123456789")))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := w.Header().Get("Content-Disposition"); got != "attachment" {
+		t.Errorf("unnamed download should omit filename: got %q", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/octet-stream" {
+		t.Errorf("wrong Content-Type: got %q", got)
+	}
+}
+
+func TestDownloadRange(t *testing.T) {
+	res := NewDownload("data.bin", bytes.NewReader([]byte("0123456789"))).WithETag(`"v1"`)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	req.Header.Set("If-Range", `"v1"`)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("wrong status code: want %d, got %d", http.StatusPartialContent, w.Code)
+	}
+	if got := w.Body.String(); got != "2345" {
+		t.Errorf("wrong body: want %q, got %q", "2345", got)
+	}
+	if got := w.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Errorf("wrong Content-Range: got %q", got)
+	}
+
+	// stale If-Range serves full content
+	w = httptest.NewRecorder()
+	req.Header.Set("If-Range", `"v0"`)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusOK || w.Body.Len() != 10 {
+		t.Errorf("expected full content for stale If-Range, got %d with %d bytes", w.Code, w.Body.Len())
+	}
+}
+
+func TestDownloadNotModified(t *testing.T) {
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		reader func() *Download
+	}{
+		{"seeker etag", "If-None-Match", `"v1"`, func() *Download {
+			return NewDownload("a.txt", strings.NewReader("abc")).WithETag(`"v1"`)
+		}},
+		{"seeker modtime", "If-Modified-Since", modtime.Format(http.TimeFormat), func() *Download {
+			return NewDownload("a.txt", strings.NewReader("abc")).WithModTime(modtime)
+		}},
+		{"reader etag", "If-None-Match", `W/"v1"`, func() *Download {
+			return NewDownload("a.txt", onlyReader{strings.NewReader("abc")}).WithETag(`"v1"`)
+		}},
+		{"reader modtime", "If-Modified-Since", modtime.Format(http.TimeFormat), func() *Download {
+			return NewDownload("a.txt", onlyReader{strings.NewReader("abc")}).WithModTime(modtime)
+		}},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(tt.header, tt.value)
+		if err := tt.reader().Handle(w, req); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if w.Code != http.StatusNotModified {
+			t.Errorf("%s: wrong status code: want %d, got %d", tt.name, http.StatusNotModified, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("%s: expected empty body, got %q", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestReaderContentLength(t *testing.T) {
+	res := NewContentReader(http.StatusCreated, onlyReader{strings.NewReader("hello")}, []string{"text/plain"})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusCreated {
+		t.Errorf("wrong status code: want %d, got %d", http.StatusCreated, w.Code)
+	}
+	if got := w.Header().Get("Content-Length"); got != "" {
+		t.Errorf("unexpected Content-Length for reader of unknown size: %q", got)
+	}
+
+	res = NewContentReader(http.StatusOK, strings.NewReader("hello"), []string{"text/plain"})
+	w = httptest.NewRecorder()
+	req.Header.Set("Range", "bytes=-2")
+	if err := res.Handle(w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := ioutil.ReadAll(w.Body)
+	if w.Code != http.StatusPartialContent || string(body) != "lo" {
+		t.Errorf("wrong range response: %d %q", w.Code, body)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/plain" {
+		t.Errorf("wrong Content-Type: got %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package response
+
+// Payloader is implemented by Responses which expose the data they render
+type Payloader interface {
+	Payload() interface{}
+}
+
+// PayloadOf returns data rendered by given Responder.
+// Decorated Responders are unwrapped until Payloader is found.
+func PayloadOf(res Responder) (interface{}, bool) {
+	for res != nil {
+		if p, ok := res.(Payloader); ok {
+			return p.Payload(), true
+		}
+		d, ok := res.(*Decorator)
+		if !ok {
+			return nil, false
+		}
+		res = d.Unwrap()
+	}
+	return nil, false
+}
+
+// ReplacePayload returns copy of given Responder which renders given data.
+// Decorations (status code, headers and cookies) of the Responder are kept.
+// It returns false, together with unchanged Responder,
+// when payload of the Responder can not be replaced.
+func ReplacePayload(res Responder, data interface{}) (Responder, bool) {
+	switch v := res.(type) {
+	case *Render:
+		return v.WithPayload(data)
+	case *Decorator:
+		inner, ok := ReplacePayload(v.inner, data)
+		if !ok {
+			return res, false
+		}
+		d := *v
+		d.inner = inner
+		return &d, true
+	}
+	return res, false
+}
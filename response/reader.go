@@ -0,0 +1,55 @@
+package response
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// ContentReader is io.Reader Response supporting Range and conditional requests
+type ContentReader struct {
+	content
+	code        int
+	contentType []string
+}
+
+// NewContentReader creates io.Reader Response for given http code, reader and content type.
+//
+// When reader implements io.ReadSeeker and code is 200, Range and conditional requests are supported.
+// Content-Length header is set whenever the size of the reader is known.
+func NewContentReader(code int, reader io.Reader, contentType []string) *ContentReader {
+	return &ContentReader{
+		content: content{
+			reader: reader,
+		},
+		code:        code,
+		contentType: contentType,
+	}
+}
+
+// WithModTime sets modification time used for Last-Modified header
+// and If-Modified-Since, If-Unmodified-Since and If-Range request headers
+func (rr *ContentReader) WithModTime(modtime time.Time) *ContentReader {
+	rr.modtime = modtime
+	return rr
+}
+
+// WithETag sets ETag header used for If-Match, If-None-Match
+// and If-Range request headers. Value has to be quoted, eg. `"v1"` or `W/"v1"`
+func (rr *ContentReader) WithETag(etag string) *ContentReader {
+	rr.etag = etag
+	return rr
+}
+
+func (rr *ContentReader) Status() int {
+	return rr.code
+}
+
+func (rr *ContentReader) Handle(w http.ResponseWriter, r *http.Request) error {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 && len(rr.contentType) > 0 {
+		header["Content-Type"] = rr.contentType
+	}
+
+	return rr.serve(w, r, rr.code)
+}
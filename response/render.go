@@ -70,7 +70,8 @@ func NewData(code int, data []byte, contentType []string) *Render {
 	}
 }
 
-// NewReader creates io.Reader render Response for given http code, reader and content type
+// NewReader creates io.Reader render Response for given http code, reader and content type.
+// Use NewContentReader to support Range and conditional requests.
 func NewReader(code int, reader io.Reader, contentType []string) *Render {
 	return &Render{
 		Renderer: render.Reader{
@@ -80,3 +81,49 @@ func NewReader(code int, reader io.Reader, contentType []string) *Render {
 		code: code,
 	}
 }
+
+// Payload returns data rendered by Render Response.
+// It returns nil for unknown renderers and streamed content, eg. NewReader.
+func (rr *Render) Payload() interface{} {
+	switch v := rr.Renderer.(type) {
+	case render.JSON:
+		return v.Data
+	case render.XML:
+		return v.Data
+	case render.Text:
+		return v.Data
+	case render.Data:
+		return v.Data
+	}
+	return nil
+}
+
+// WithPayload returns copy of Render Response which renders given data
+// using the same renderer kind and status code.
+// It returns false when data can not be rendered by the renderer.
+func (rr *Render) WithPayload(data interface{}) (*Render, bool) {
+	res := &Render{code: rr.code}
+
+	switch v := rr.Renderer.(type) {
+	case render.JSON:
+		res.Renderer = render.JSON{Data: data}
+	case render.XML:
+		res.Renderer = render.XML{Data: data}
+	case render.Text:
+		text, ok := data.(string)
+		if !ok {
+			return rr, false
+		}
+		res.Renderer = render.Text{Data: text}
+	case render.Data:
+		b, ok := data.([]byte)
+		if !ok {
+			return rr, false
+		}
+		res.Renderer = render.Data{Data: b, CType: v.CType}
+	default:
+		return rr, false
+	}
+
+	return res, true
+}
@@ -0,0 +1,61 @@
+package response
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// NewCreated creates 201 Created Response with Location header pointing
+// to the created resource and JSON rendered data.
+// Response has no body when data is nil.
+func NewCreated(location string, data interface{}) *Decorator {
+	return withLocation(http.StatusCreated, location, data)
+}
+
+// NewAccepted creates 202 Accepted Response with Location header pointing
+// to the status monitor of the accepted request and JSON rendered data.
+// Response has no body when data is nil.
+func NewAccepted(location string, data interface{}) *Decorator {
+	return withLocation(http.StatusAccepted, location, data)
+}
+
+// NewNoContent creates 204 No Content Response
+func NewNoContent() *Headers {
+	return NewHeaders(http.StatusNoContent, nil)
+}
+
+// NewNotModified creates 304 Not Modified Response with given ETag header.
+// ETag header is omitted when etag is empty.
+func NewNotModified(etag string) *Headers {
+	headers := map[string]string{}
+	if etag != "" {
+		headers["ETag"] = etag
+	}
+	return NewHeaders(http.StatusNotModified, headers)
+}
+
+// NewTooManyRequests creates 429 Too Many Requests Error Response with
+// Retry-After header set to the number of seconds client should wait before retrying.
+// Retry-After header is omitted when retryAfter is not positive.
+func NewTooManyRequests(retryAfter time.Duration, err error) *Decorator {
+	var header http.Header
+	if retryAfter > 0 {
+		seconds := int64(math.Ceil(retryAfter.Seconds()))
+		header = http.Header{"Retry-After": {strconv.FormatInt(seconds, 10)}}
+	}
+	return WithHeaders(NewError(http.StatusTooManyRequests, err), header)
+}
+
+func withLocation(code int, location string, data interface{}) *Decorator {
+	var header http.Header
+	if location != "" {
+		header = http.Header{"Location": {location}}
+	}
+
+	if data == nil {
+		return WithHeaders(NewHeaders(code, nil), header)
+	}
+	return WithHeaders(NewJSON(code, data), header)
+}
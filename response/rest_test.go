@@ -0,0 +1,69 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRESTResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		res     Responder
+		code    int
+		headers map[string]string
+		body    string
+	}{
+		{"created", NewCreated("/users/42", map[string]int{"id": 42}), http.StatusCreated,
+			map[string]string{"Location": "/users/42", "Content-Type": "application/json; charset=utf-8"}, `{"id":42}`},
+		{"created without body", NewCreated("/users/42", nil), http.StatusCreated,
+			map[string]string{"Location": "/users/42"}, ""},
+		{"accepted", NewAccepted("/jobs/7", nil), http.StatusAccepted,
+			map[string]string{"Location": "/jobs/7"}, ""},
+		{"no content", NewNoContent(), http.StatusNoContent, nil, ""},
+		{"not modified", NewNotModified(`"v1"`), http.StatusNotModified,
+			map[string]string{"ETag": `"v1"`}, ""},
+		{"too many requests", NewTooManyRequests(1500*time.Millisecond, errors.New("slow down")), http.StatusTooManyRequests,
+			map[string]string{"Retry-After": "2"}, "slow down"},
+		{"too many requests without retry", NewTooManyRequests(0, errors.New("slow down")), http.StatusTooManyRequests,
+			map[string]string{"Retry-After": ""}, "slow down"},
+	}
+
+	for _, tt := range tests {
+		if tt.res.Status() != tt.code {
+			t.Errorf("%s: wrong Status(): want %d, got %d", tt.name, tt.code, tt.res.Status())
+		}
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if err := tt.res.Handle(w, req); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		if w.Code != tt.code {
+			t.Errorf("%s: wrong status code: want %d, got %d", tt.name, tt.code, w.Code)
+		}
+		for k, v := range tt.headers {
+			if got := w.Header().Get(k); got != v {
+				t.Errorf("%s: wrong %s header: want %q, got %q", tt.name, k, v, got)
+			}
+		}
+		if got := w.Body.String(); got != tt.body {
+			t.Errorf("%s: wrong body: want %q, got %q", tt.name, tt.body, got)
+		}
+	}
+}
+
+func TestRESTResponsesUnwrap(t *testing.T) {
+	if _, ok := NewCreated("/users/42", map[string]int{"id": 42}).Unwrap().(*Render); !ok {
+		t.Error("created response with data should decorate Render")
+	}
+	if _, ok := NewAccepted("/jobs/7", nil).Unwrap().(*Headers); !ok {
+		t.Error("accepted response without data should decorate Headers")
+	}
+	if _, ok := NewTooManyRequests(0, errors.New("slow down")).Unwrap().(*Error); !ok {
+		t.Error("too many requests response should decorate Error")
+	}
+}
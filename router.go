@@ -19,6 +19,9 @@ type Router struct {
 	paramsPool sync.Pool
 	maxParams  uint16
 	mws        *MiddlewareStack
+	fallback   *Route
+
+	interceptors []Interceptor
 
 	// Enables automatic redirection if the current route can't be matched but a
 	// handler for the path with (without) the trailing slash exists.
@@ -139,6 +142,12 @@ func (r *Router) Use(mw ...MiddlewareHandlerFunc) {
 	r.mws.Append(mw...)
 }
 
+// Intercept appends one or more interceptors to router interceptors.
+// Interceptors are applied to handlers registered after the call.
+func (r *Router) Intercept(interceptors ...Interceptor) {
+	r.interceptors = append(r.interceptors, interceptors...)
+}
+
 // Group creates a new router group.
 //
 // You should add all the routes that have common middlewares or the same path prefix.
@@ -153,6 +162,7 @@ func (r *Router) Group(path string, middlewares ...MiddlewareHandlerFunc) *Route
 		root:                   false,
 		basePath:               joinPaths(r.basePath, path),
 		mws:                    r.mws.Clone(middlewares...),
+		interceptors:           append([]Interceptor{}, r.interceptors...),
 		trees:                  r.trees,
 		Body404:                r.Body404,
 		Body405:                r.Body405,
@@ -165,6 +175,38 @@ func (r *Router) Attach(prefix string, router *Router) {
 		path := joinPaths(prefix, route.Path)
 		r.Handle(route.Method, path, route.Handler, route.Mws.stack...)
 	}
+	if fb := router.fallback; fb != nil {
+		r.handleFallback(prefix, fb.Handler, fb.Mws.stack...)
+	}
+}
+
+// handleFallback registers handler for GET and HEAD requests under given path prefix,
+// handler receives requested path under the prefix as `filepath` param.
+// Handler registered for the root path `/` handles requests which do not match any route
+// with middlewares of the router at the time of the request, see StaticWithOptions.
+func (r *Router) handleFallback(prefix string, handler HandlerFunc, middlewares ...MiddlewareHandlerFunc) {
+	if joinPaths(r.basePath, prefix) != "/" {
+		p := strings.TrimSuffix(prefix, "/") + "/*filepath"
+		r.GET(p, handler, middlewares...)
+		r.HEAD(p, handler, middlewares...)
+		return
+	}
+
+	top := r
+	for top.parent != nil {
+		top = top.parent
+	}
+	mws := r.mws
+	if len(middlewares) > 0 {
+		mws = r.mws.Clone(middlewares...)
+	}
+	top.fallback = &Route{
+		router:  r,
+		Method:  http.MethodGet,
+		Path:    "/",
+		Mws:     mws,
+		Handler: intercept(handler, r.interceptors),
+	}
 }
 
 // AttachRoutes to current routes
@@ -217,7 +259,7 @@ func (r *Router) Handle(method, path string, handler HandlerFunc, middlewares ..
 		Method:  method,
 		Path:    path,
 		Mws:     r.mws.Clone(middlewares...),
-		Handler: handler,
+		Handler: intercept(handler, r.interceptors),
 	}
 
 	root.addRoute(path, route)
@@ -334,6 +376,11 @@ func (r *Router) dispatchRequest(w http.ResponseWriter, req *http.Request) Respo
 		}
 	}
 
+	// fallback handles only requests which are not handled by any route
+	if r.fallback != nil && (req.Method == http.MethodGet || req.Method == http.MethodHead) {
+		return r.fallback.HandleRequest(w, req)
+	}
+
 	return ResponseError(http.StatusNotFound, errors.New(r.Body404))
 }
 
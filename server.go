@@ -0,0 +1,48 @@
+package flow
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ServerConfigurer interface is implemented by module factories which customise
+// http server before application starts serving requests, eg. to set TLSNextProto
+// or BaseContext. ConfigureServer is invoked after the server is configured
+// from Options, imported modules are invoked before modules importing them.
+type ServerConfigurer interface {
+	ConfigureServer(srv *http.Server) error
+}
+
+// newServer creates http server configured from module Options and by module factories.
+// Returned reloader loads TLS certificate when application is served over TLS.
+func (m *Module) newServer() (*http.Server, *certReloader, error) {
+	opts := m.options.serverOptions()
+	srv := &http.Server{
+		Handler:           m.router,
+		ReadTimeout:       opts.ReadTimeout,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		MaxHeaderBytes:    opts.MaxHeaderBytes,
+		ErrorLog:          opts.ErrorLog,
+		ConnState:         opts.ConnState,
+	}
+
+	var reloader *certReloader
+	if m.options.TLSOptions.enabled() {
+		cfg, r, err := newTLSConfig(m.options.TLSOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		srv.TLSConfig, reloader = cfg, r
+	}
+
+	for _, module := range m.importOrder() {
+		if sc, ok := module.factory.(ServerConfigurer); ok {
+			if err := sc.ConfigureServer(srv); err != nil {
+				return nil, nil, fmt.Errorf("unable to configure HTTP server by module `%s`. Error: %w", module.name, err)
+			}
+		}
+	}
+	return srv, reloader, nil
+}
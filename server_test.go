@@ -0,0 +1,126 @@
+package flow
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type configuredModule struct {
+	testModule
+	err error
+}
+
+func (cm *configuredModule) ConfigureServer(srv *http.Server) error {
+	srv.ReadTimeout *= 2
+	return cm.err
+}
+
+func TestModuleServerOptions(t *testing.T) {
+	dev, prod := NewServerOptions("development"), NewServerOptions("production")
+	if dev.ReadHeaderTimeout == 0 || dev.WriteTimeout != 0 || prod.ReadTimeout == 0 || prod.WriteTimeout == 0 || prod.MaxHeaderBytes >= dev.MaxHeaderBytes {
+		t.Errorf("wrong default server options: %+v, %+v", dev, prod)
+	}
+
+	opts := NewOptions()
+	opts.ServerOptions = prod
+	m, err := Bootstrap(&servedModule{
+		testModule: testModule{
+			modules: []Provider{
+				NewProvider(func() *configuredModule { return &configuredModule{} }),
+			},
+		},
+		opts: opts,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv, _, err := m.newServer()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if srv.ReadTimeout != 2*prod.ReadTimeout || srv.WriteTimeout != prod.WriteTimeout || srv.MaxHeaderBytes != prod.MaxHeaderBytes || srv.Handler != m.router {
+		t.Errorf("server is not configured: %+v", srv)
+	}
+
+	errConfigure := errors.New("configure failed")
+	m.modules[0].factory.(*configuredModule).err = errConfigure
+	if _, _, err := m.newServer(); !errors.Is(err, errConfigure) {
+		t.Errorf("expected configure error, got: %v", err)
+	}
+}
+
+func TestModuleServerOptionsEnv(t *testing.T) {
+	prod := NewServerOptions("production")
+
+	opts := NewOptions()
+	opts.Env = "production"
+	m, err := Bootstrap(&servedModule{opts: opts})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv, _, err := m.newServer()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if srv.ReadTimeout != prod.ReadTimeout || srv.WriteTimeout != prod.WriteTimeout || srv.MaxHeaderBytes != prod.MaxHeaderBytes {
+		t.Errorf("server should use defaults of production environment: %+v", srv)
+	}
+
+	if so := (Options{Env: "production"}).serverOptions(); so.ReadHeaderTimeout != prod.ReadHeaderTimeout || so.IdleTimeout != prod.IdleTimeout {
+		t.Errorf("zero server options should use defaults of the environment: %+v", so)
+	}
+
+	// set options are kept, even when they equal defaults of development environment
+	dev := NewServerOptions(defaultEnv)
+	opts.WriteTimeout = time.Hour
+	opts.ReadHeaderTimeout = dev.ReadHeaderTimeout
+	opts.MaxHeaderBytes = dev.MaxHeaderBytes
+	opts.ReadTimeout = -1
+	so := opts.serverOptions()
+	if so.WriteTimeout != time.Hour || so.ReadHeaderTimeout != dev.ReadHeaderTimeout || so.MaxHeaderBytes != dev.MaxHeaderBytes {
+		t.Errorf("set server options should be kept: %+v", so)
+	}
+	if so.ReadTimeout != 0 || so.IdleTimeout != prod.IdleTimeout {
+		t.Errorf("negative timeout should disable the timeout, zero should use the default: %+v", so)
+	}
+}
+
+func TestModuleServeConnState(t *testing.T) {
+	states := make(chan http.ConnState, 10)
+	opts := servedOptions(t)
+	opts.ConnState = func(conn net.Conn, state http.ConnState) {
+		states <- state
+	}
+
+	m, err := Bootstrap(&servedModule{opts: opts})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	served := serveModule(t, m)
+
+	conn, err := net.Dial("unix", opts.Addr[5:])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn.Close()
+
+	select {
+	case state := <-states:
+		if state != http.StateNew {
+			t.Errorf("wrong connection state: %s", state)
+		}
+	case <-time.After(time.Second):
+		t.Error("ConnState hook is not invoked")
+	}
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	if err := <-served; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package flow
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// IsolatedProvider interface is implemented by providers of modules which are
+// not shared with other importing modules.
+//
+// Module imported by several modules using providers with the same constructor, or using
+// ModuleProvider of the same name, is created once, by the first importing module, and its exports
+// are registered to all importing modules. Modules created by different constructors of the same
+// module factory are not shared, as the constructors can configure modules differently.
+//
+// Routers of shared module are registered once, under the router of the first importing
+// module which registers sub routers, see RouterFactory.RegisterSubRouters.
+//
+// Isolated modules are created for every import, eg. to use separate connection pool,
+// and their routers are registered under every importing module.
+type IsolatedProvider interface {
+	Provider
+	Isolated() bool
+}
+
+type isolatedProvider struct {
+	instanceProvider
+}
+
+func (ip *isolatedProvider) Isolated() bool {
+	return true
+}
+
+// NewIsolatedProvider creates provider of the module which is created
+// for every import instead of being shared, see IsolatedProvider
+func NewIsolatedProvider(constructor interface{}) IsolatedProvider {
+	return &isolatedProvider{
+		instanceProvider: instanceProvider{
+			constructor: constructor,
+		},
+	}
+}
+
+// moduleKey identifies shared module by the type of module factory and the name
+// of ModuleProvider, or by the constructor of modules not created by ModuleProvider
+type moduleKey struct {
+	typ         reflect.Type
+	name        string
+	constructor uintptr
+}
+
+// exportedInstance is an instance created by exported provider of the module
+type exportedInstance struct {
+	provider Provider
+	obj      interface{}
+}
+
+// isolated returns true if module provider creates isolated modules
+func isolated(p Provider) bool {
+	ip, ok := p.(IsolatedProvider)
+	return ok && ip.Isolated()
+}
+
+// moduleKeyOf returns identity of module created by provider. Module factory type
+// is inspected from provider constructor when factory is not created yet.
+// Returned key has nil type when identity can not be determined.
+func moduleKeyOf(p Provider, factory ModuleFactory) moduleKey {
+	var key moduleKey
+	if mp, ok := p.(ModuleProvider); ok {
+		key.name = mp.Name()
+	} else if cp, ok := p.(ConstructorProvider); ok {
+		if fn := reflect.ValueOf(cp.Constructor()); fn.Kind() == reflect.Func {
+			key.constructor = fn.Pointer()
+		}
+	}
+	if factory != nil {
+		key.typ = reflect.TypeOf(factory)
+		return key
+	}
+	if c, err := inspectProvider(p); err == nil && c != nil && c.Result.Kind() != reflect.Interface {
+		key.typ = c.Result
+	}
+	return key
+}
+
+// sharedModule returns already created module imported by given provider,
+// or nil when the module is not created yet
+func (m *Module) sharedModule(p Provider, factory ModuleFactory) (*Module, error) {
+	key := moduleKeyOf(p, factory)
+	if key.typ == nil {
+		return nil, nil
+	}
+
+	// modules under construction are not registered yet
+	for a := m; a != nil; a = a.parent {
+		if reflect.TypeOf(a.factory) == key.typ && a.qualifier == key.name {
+			return nil, fmt.Errorf("module import cycle detected in module chain %s -> %s", m.chain(), a.name)
+		}
+	}
+
+	shared, ok := m.root().shared[key]
+	if !ok {
+		return nil, nil
+	}
+	if mp, ok := p.(ModuleProvider); ok && !reflect.DeepEqual(mp.Config(), shared.config) {
+		return nil, fmt.Errorf("module `%s` is already imported with different config", shared.name)
+	}
+	return shared, nil
+}
+
+// addShared registers module created by given provider, which can be imported by other modules
+func (m *Module) addShared(p Provider, shared *Module) {
+	root := m.root()
+	if root.shared == nil {
+		root.shared = map[moduleKey]*Module{}
+	}
+	root.shared[moduleKeyOf(p, shared.factory)] = shared
+}
+
+// importShared imports already created module and registers its exports to the module container
+func (m *Module) importShared(shared *Module) {
+	for _, e := range shared.exported {
+		// global instances are already registered to all modules
+		if gp, ok := e.provider.(GlobalProvider); ok && gp.Global() {
+			continue
+		}
+		shared.registerExport(m.container, e.provider, e.obj)
+	}
+	m.modules = append(m.modules, shared)
+}
+
+// routeOnce returns true when routers of the module are not registered yet,
+// routers of shared modules are registered by the first module registering sub routers
+func (m *Module) routeOnce(module *Module) bool {
+	root := m.root()
+	if root.routed == nil {
+		root.routed = map[*Module]bool{}
+	}
+	if root.routed[module] {
+		return false
+	}
+	root.routed[module] = true
+	return true
+}
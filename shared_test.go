@@ -0,0 +1,177 @@
+package flow
+
+import (
+	"strings"
+	"testing"
+)
+
+// poolModule is ModuleFactory imported by several modules in shared module tests
+type poolModule struct {
+	testModule
+}
+
+type subRouter struct {
+	path string
+}
+
+func (r subRouter) Path() string                       { return r.path }
+func (subRouter) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (subRouter) RegisterSubRouters() bool             { return true }
+func (subRouter) ProvideHandlers() []Provider          { return nil }
+
+// poolTree creates root module importing two modules, which both import poolModule
+// using providers with the same constructor
+func poolTree(pool func(constructor interface{}) Provider, constructed *int, repos *[]*testRepo) *testModule {
+	newPool := func() *poolModule {
+		*constructed++
+		return &poolModule{testModule{
+			exports: []Provider{
+				NewProvider(func() *testDB { return &testDB{} }),
+			},
+			routers: []Provider{
+				NewProvider(func() subRouter { return subRouter{path: "/pool"} }),
+			},
+		}}
+	}
+	importer := func(path string) testModule {
+		return testModule{
+			imports: []Provider{
+				NewProvider(func(db *testDB) *testRepo {
+					r := &testRepo{db: db}
+					*repos = append(*repos, r)
+					return r
+				}),
+			},
+			modules: []Provider{pool(newPool)},
+			routers: []Provider{
+				NewProvider(func() subRouter { return subRouter{path: path} }),
+			},
+		}
+	}
+
+	return &testModule{
+		modules: []Provider{
+			NewProvider(func() *childModule { return &childModule{importer("/a")} }),
+			NewProvider(func() *siblingModule { return &siblingModule{importer("/b")} }),
+		},
+		routers: []Provider{
+			NewProvider(func() subRouter { return subRouter{path: "/"} }),
+		},
+	}
+}
+
+func TestModuleShared(t *testing.T) {
+	var constructed int
+	var repos []*testRepo
+
+	m, err := Bootstrap(poolTree(NewProvider, &constructed, &repos))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if constructed != 1 {
+		t.Errorf("shared module should be created once, created %d times", constructed)
+	}
+	if len(repos) != 2 || repos[0].db != repos[1].db {
+		t.Errorf("exports of shared module are not shared: %+v", repos)
+	}
+
+	g := m.Graph()
+	if len(g.Modules) != 4 {
+		t.Fatalf("wrong number of modules: %d", len(g.Modules))
+	}
+	if g.Modules[1].Imports[0] != "flow.poolModule" || g.Modules[3].Imports[0] != "flow.poolModule" {
+		t.Errorf("wrong module imports: %+v, %+v", g.Modules[1], g.Modules[3])
+	}
+	if routers := g.Modules[2].Routers; len(routers) != 1 || routers[0].Path != "/a/pool" {
+		t.Errorf("routers of shared module should be registered once: %+v", routers)
+	}
+}
+
+func TestModuleIsolated(t *testing.T) {
+	var constructed int
+	var repos []*testRepo
+
+	pool := func(constructor interface{}) Provider { return NewIsolatedProvider(constructor) }
+	if _, err := Bootstrap(poolTree(pool, &constructed, &repos)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if constructed != 2 {
+		t.Errorf("isolated module should be created for every import, created %d times", constructed)
+	}
+	if len(repos) != 2 || repos[0].db == repos[1].db {
+		t.Errorf("exports of isolated modules should not be shared: %+v", repos)
+	}
+}
+
+func TestModuleSharedByConstructor(t *testing.T) {
+	var constructed, configured int
+	newPool := func() *poolModule {
+		constructed++
+		return &poolModule{}
+	}
+	newConfiguredPool := func() *poolModule {
+		configured++
+		return &poolModule{}
+	}
+
+	_, err := Bootstrap(&testModule{
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{modules: []Provider{NewProvider(newPool)}}}
+			}),
+			NewProvider(newPool),
+			NewProvider(newConfiguredPool),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if constructed != 1 {
+		t.Errorf("modules imported by providers with the same constructor should be shared, created %d times", constructed)
+	}
+	// modules created by different constructors can be configured differently
+	if configured != 1 {
+		t.Errorf("modules created by different constructors should not be shared, created %d times", configured)
+	}
+}
+
+func TestModuleImportCycle(t *testing.T) {
+	var newChild func() *childModule
+	newChild = func() *childModule {
+		return &childModule{testModule{
+			modules: []Provider{
+				NewProvider(func() *siblingModule {
+					return &siblingModule{testModule{
+						modules: []Provider{NewProvider(newChild)},
+					}}
+				}),
+			},
+		}}
+	}
+
+	_, err := Bootstrap(&testModule{
+		modules: []Provider{NewProvider(newChild)},
+	})
+	if err == nil || !strings.Contains(err.Error(), "module import cycle detected in module chain flow.testModule -> flow.childModule -> flow.siblingModule -> flow.childModule") {
+		t.Errorf("expected module import cycle error, got: %v", err)
+	}
+}
+
+func TestModuleSharedConfigMismatch(t *testing.T) {
+	_, err := Bootstrap(&testModule{
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					modules: []Provider{NewModuleProvider("primary", dbConfig{DSN: "a"}, newDBModule)},
+				}}
+			}),
+			NewModuleProvider("primary", dbConfig{DSN: "b"}, newDBModule),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "module `flow.dbModule[primary]` is already imported with different config") {
+		t.Errorf("expected config mismatch error, got: %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+//go:build go1.16
+// +build go1.16
+
+package flow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-flow/flow/v2/response"
+)
+
+const defaultStaticIndex = "index.html"
+
+// StaticOptions holds static file serving configuration Options
+type StaticOptions struct {
+	// Index is the file served for directory requests.
+	Index string
+
+	// CacheControl is the Cache-Control header value set for served files,
+	// eg. "public, max-age=31536000, immutable". Header is omitted when empty.
+	CacheControl string
+
+	// IndexCacheControl is the Cache-Control header value set for directory
+	// index files and SPA fallback file, eg. "no-cache".
+	// Header is omitted when empty.
+	IndexCacheControl string
+
+	// If enabled, weak ETag header computed from file size
+	// and modification time is set for served files.
+	ETag bool
+
+	// If enabled, precompressed `.br` and `.gz` siblings of the requested file
+	// are served when they exist and client accepts the encoding.
+	Precompressed bool
+
+	// SPAFallback is the file served for GET and HEAD requests
+	// which do not match any file, eg. "index.html" for client-side routes of
+	// single page applications. Requests for paths with file extension
+	// are not served with fallback file, so missing assets are reported as 404.
+	SPAFallback string
+}
+
+// NewStaticOptions creates new static file serving Options instance with default values
+func NewStaticOptions() StaticOptions {
+	return StaticOptions{
+		Index:         defaultStaticIndex,
+		ETag:          true,
+		Precompressed: true,
+	}
+}
+
+// StaticDir serves files from given directory of the local file system
+// under given path prefix using default static Options.
+func (r *Router) StaticDir(prefix, dir string) {
+	r.Static(prefix, os.DirFS(dir))
+}
+
+// Static serves files from given file system under given path prefix using default static Options.
+//
+// Files are served using catch-all route `prefix/*filepath` for GET and HEAD methods,
+// so router middlewares are applied to static files as well.
+func (r *Router) Static(prefix string, fsys fs.FS) {
+	r.StaticWithOptions(prefix, fsys, NewStaticOptions())
+}
+
+// StaticWithOptions serves files from given file system under given path prefix and Options.
+//
+// Files are served using catch-all route `prefix/*filepath` for GET and HEAD methods.
+// Catch-all route can not share the path with other routes, so files served
+// from the root path `/` are served only for GET and HEAD requests that do not match any route
+// and are not answered with 405 Method Not Allowed, with middlewares of the router at the time
+// of the request and interceptors of the router. That way API routes always win over static
+// files and SPA fallback. Files are served relative to the mount point when the router is attached
+// to another router, see Router.Attach.
+//
+// Requests for directories without trailing slash are redirected to the path with trailing slash.
+func (r *Router) StaticWithOptions(prefix string, fsys fs.FS, opts StaticOptions) {
+	if fsys == nil {
+		panic("static file system must not be nil")
+	}
+	if len(prefix) < 1 || prefix[0] != '/' {
+		panic("path must begin with '/' in path '" + prefix + "'")
+	}
+	if opts.Index == "" {
+		opts.Index = defaultStaticIndex
+	}
+
+	sh := &staticHandler{
+		fsys: fsys,
+		opts: opts,
+	}
+	r.handleFallback(prefix, sh.serve)
+}
+
+type staticHandler struct {
+	fsys fs.FS
+	opts StaticOptions
+}
+
+func (sh *staticHandler) serve(r *http.Request) Response {
+	// files are served relative to the path the handler is mounted at,
+	// requests handled by root fallback do not have the `filepath` param
+	name, ok := ParamsFromContext(r.Context()).Get("filepath")
+	if !ok {
+		name = r.URL.Path
+	}
+	// clean path removes all `..` elements, so files outside of the
+	// file system root can not be requested
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) || strings.Contains(name, `\`) {
+		return ResponseError(http.StatusNotFound, errors.New(default404Body))
+	}
+
+	cacheControl := sh.opts.CacheControl
+
+	fi, err := fs.Stat(sh.fsys, name)
+	if err == nil && fi.IsDir() {
+		// relative links of directory index are resolved against directory path
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			u := *r.URL
+			u.Path += "/"
+			return ResponseRedirect(http.StatusMovedPermanently, u.String())
+		}
+		name = path.Join(name, sh.opts.Index)
+		cacheControl = sh.opts.IndexCacheControl
+		fi, err = fs.Stat(sh.fsys, name)
+	}
+
+	if err != nil || fi.IsDir() {
+		if sh.opts.SPAFallback == "" || path.Ext(name) != "" {
+			return ResponseError(http.StatusNotFound, errors.New(default404Body))
+		}
+		name = sh.opts.SPAFallback
+		cacheControl = sh.opts.IndexCacheControl
+		if fi, err = fs.Stat(sh.fsys, name); err != nil || fi.IsDir() {
+			return ResponseError(http.StatusNotFound, errors.New(default404Body))
+		}
+	}
+
+	sf := &staticFile{
+		fsys:         sh.fsys,
+		name:         name,
+		info:         fi,
+		etag:         sh.opts.ETag,
+		cacheControl: cacheControl,
+	}
+
+	if sh.opts.Precompressed {
+		sf.vary = true
+		sf.negotiateEncoding(r.Header.Get("Accept-Encoding"))
+	}
+
+	return sf
+}
+
+var staticEncodings = []struct {
+	encoding  string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// staticFile is Response which serves single file from file system.
+// File is opened when Response is handled.
+type staticFile struct {
+	fsys         fs.FS
+	name         string
+	info         fs.FileInfo
+	encoding     string
+	etag         bool
+	vary         bool
+	cacheControl string
+}
+
+func (sf *staticFile) negotiateEncoding(acceptEncoding string) {
+	for _, enc := range staticEncodings {
+		if !acceptsEncoding(acceptEncoding, enc.encoding) {
+			continue
+		}
+		fi, err := fs.Stat(sf.fsys, sf.name+enc.extension)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		sf.name += enc.extension
+		sf.info = fi
+		sf.encoding = enc.encoding
+		return
+	}
+}
+
+func (sf *staticFile) Status() int {
+	return http.StatusOK
+}
+
+func (sf *staticFile) Handle(w http.ResponseWriter, r *http.Request) error {
+	f, err := sf.fsys.Open(sf.name)
+	if err != nil {
+		return fmt.Errorf("unable to open static file `%s`. Error: %w", sf.name, err)
+	}
+	defer f.Close()
+
+	var content io.Reader = f
+	if _, ok := f.(io.ReadSeeker); !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return fmt.Errorf("unable to read static file `%s`. Error: %w", sf.name, err)
+		}
+		content = bytes.NewReader(data)
+	}
+
+	h := w.Header()
+
+	// content type is resolved from original file name
+	origName := strings.TrimSuffix(sf.name, path.Ext(sf.name))
+	if sf.encoding == "" {
+		origName = sf.name
+	}
+	ctype := mime.TypeByExtension(path.Ext(origName))
+	if ctype == "" && sf.encoding != "" {
+		ctype = "application/octet-stream"
+	}
+	if ctype != "" {
+		h.Set("Content-Type", ctype)
+	}
+
+	if sf.encoding != "" {
+		h.Set("Content-Encoding", sf.encoding)
+	}
+	if sf.vary {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	if sf.cacheControl != "" {
+		h.Set("Cache-Control", sf.cacheControl)
+	}
+
+	res := response.NewContentReader(http.StatusOK, content, nil).WithModTime(sf.info.ModTime())
+	if sf.etag {
+		res.WithETag(fmt.Sprintf(`W/"%x-%x"`, sf.info.Size(), sf.info.ModTime().UnixNano()))
+	}
+
+	return res.Handle(w, r)
+}
+
+// acceptsEncoding reports whether Accept-Encoding header value
+// contains given encoding with non zero quality
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		params := ""
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			part, params = strings.TrimSpace(part[:i]), part[i+1:]
+		}
+		if !strings.EqualFold(part, encoding) {
+			continue
+		}
+		params = strings.ReplaceAll(params, " ", "")
+		return params != "q=0" && params != "q=0.0" && params != "q=0.00" && params != "q=0.000"
+	}
+	return false
+}
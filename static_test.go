@@ -0,0 +1,221 @@
+//go:build go1.16
+// +build go1.16
+
+package flow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func staticTestFS() fstest.MapFS {
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	return fstest.MapFS{
+		"index.html":        {Data: []byte("<html>index</html>"), ModTime: modtime},
+		"app.js":            {Data: []byte("console.log('app')"), ModTime: modtime},
+		"app.js.gz":         {Data: []byte("gzipped"), ModTime: modtime},
+		"app.js.br":         {Data: []byte("brotli"), ModTime: modtime},
+		"css/site.css":      {Data: []byte("body{}"), ModTime: modtime},
+		"docs/index.html":   {Data: []byte("<html>docs</html>"), ModTime: modtime},
+		"empty/placeholder": {Data: []byte(""), ModTime: modtime},
+	}
+}
+
+func serveStatic(router *Router, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/", nil)
+	req.URL.Path = path
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRouterStatic(t *testing.T) {
+	router := NewRouter()
+	opts := NewStaticOptions()
+	opts.CacheControl = "public, max-age=3600"
+	opts.IndexCacheControl = "no-cache"
+	router.StaticWithOptions("/assets", staticTestFS(), opts)
+
+	tests := []struct {
+		path         string
+		code         int
+		body         string
+		contentType  string
+		cacheControl string
+	}{
+		{"/assets/app.js", http.StatusOK, "console.log('app')", "text/javascript; charset=utf-8", "public, max-age=3600"},
+		{"/assets/css/site.css", http.StatusOK, "body{}", "text/css; charset=utf-8", "public, max-age=3600"},
+		{"/assets/", http.StatusOK, "<html>index</html>", "text/html; charset=utf-8", "no-cache"},
+		{"/assets/docs/", http.StatusOK, "<html>docs</html>", "text/html; charset=utf-8", "no-cache"},
+		{"/assets/empty/", http.StatusNotFound, default404Body, "", ""},
+		{"/assets/missing.js", http.StatusNotFound, default404Body, "", ""},
+		{"/assets/../../etc/passwd", http.StatusNotFound, default404Body, "", ""},
+		{"/assets/css/../../../router.go", http.StatusNotFound, default404Body, "", ""},
+	}
+
+	for _, tt := range tests {
+		w := serveStatic(router, http.MethodGet, tt.path, nil)
+		if w.Code != tt.code {
+			t.Errorf("%s: wrong status code: want %d, got %d", tt.path, tt.code, w.Code)
+			continue
+		}
+		if got := w.Body.String(); got != tt.body {
+			t.Errorf("%s: wrong body: want %q, got %q", tt.path, tt.body, got)
+		}
+		if tt.contentType != "" && w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: wrong Content-Type: want %q, got %q", tt.path, tt.contentType, w.Header().Get("Content-Type"))
+		}
+		if got := w.Header().Get("Cache-Control"); got != tt.cacheControl {
+			t.Errorf("%s: wrong Cache-Control: want %q, got %q", tt.path, tt.cacheControl, got)
+		}
+	}
+}
+
+func TestRouterStaticDirRedirect(t *testing.T) {
+	router := NewRouter()
+	router.Static("/assets", staticTestFS())
+
+	w := serveStatic(router, http.MethodGet, "/assets/docs", nil)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/assets/docs/" {
+		t.Errorf("directory should be redirected to path with trailing slash: %d %q", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestRouterStaticPrecompressed(t *testing.T) {
+	router := NewRouter()
+	router.Static("/assets", staticTestFS())
+
+	tests := []struct {
+		acceptEncoding string
+		body           string
+		encoding       string
+	}{
+		{"gzip, deflate, br", "brotli", "br"},
+		{"gzip", "gzipped", "gzip"},
+		{"br;q=0, gzip", "gzipped", "gzip"},
+		{"", "console.log('app')", ""},
+	}
+
+	for _, tt := range tests {
+		w := serveStatic(router, http.MethodGet, "/assets/app.js", map[string]string{"Accept-Encoding": tt.acceptEncoding})
+		if got := w.Body.String(); got != tt.body {
+			t.Errorf("%q: wrong body: want %q, got %q", tt.acceptEncoding, tt.body, got)
+		}
+		if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("%q: wrong Content-Encoding: want %q, got %q", tt.acceptEncoding, tt.encoding, got)
+		}
+		if got := w.Header().Get("Content-Type"); got != "text/javascript; charset=utf-8" {
+			t.Errorf("%q: wrong Content-Type: got %q", tt.acceptEncoding, got)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%q: wrong Vary header: got %q", tt.acceptEncoding, got)
+		}
+	}
+}
+
+func TestRouterStaticETag(t *testing.T) {
+	router := NewRouter()
+	router.Static("/assets", staticTestFS())
+
+	w := serveStatic(router, http.MethodGet, "/assets/css/site.css", nil)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+
+	w = serveStatic(router, http.MethodGet, "/assets/css/site.css", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("wrong status code: want %d, got %d", http.StatusNotModified, w.Code)
+	}
+
+	w = serveStatic(router, http.MethodHead, "/assets/css/site.css", nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("wrong HEAD response: %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if got := w.Header().Get("Content-Length"); got != "6" {
+		t.Errorf("wrong Content-Length: got %q", got)
+	}
+}
+
+func TestRouterStaticSPA(t *testing.T) {
+	router := NewRouter()
+	router.GET("/api/users", func(r *http.Request) Response {
+		return ResponseText(http.StatusOK, "users")
+	})
+	router.POST("/api/login", func(r *http.Request) Response {
+		return ResponseNoContent()
+	})
+	router.Intercept(func(r *http.Request, res Response) Response {
+		return WithHeaders(res, http.Header{"X-Intercepted": {"yes"}})
+	})
+
+	opts := NewStaticOptions()
+	opts.SPAFallback = "index.html"
+	router.StaticWithOptions("/", staticTestFS(), opts)
+
+	// middlewares registered after static files are applied to them
+	router.Use(func(next MiddlewareFunc) MiddlewareFunc {
+		return func(w http.ResponseWriter, r *http.Request) Response {
+			w.Header().Set("X-Static", "yes")
+			return next(w, r)
+		}
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{http.MethodGet, "/api/users", http.StatusOK, "users"},
+		{http.MethodGet, "/app.js", http.StatusOK, "console.log('app')"},
+		{http.MethodGet, "/", http.StatusOK, "<html>index</html>"},
+		{http.MethodGet, "/users/42/profile", http.StatusOK, "<html>index</html>"},
+		{http.MethodGet, "/missing.png", http.StatusNotFound, default404Body},
+		{http.MethodPost, "/users/42", http.StatusNotFound, default404Body},
+		{http.MethodGet, "/api/login", http.StatusMethodNotAllowed, default405Body},
+	}
+
+	w := serveStatic(router, http.MethodGet, "/app.js", nil)
+	if w.Header().Get("X-Static") != "yes" {
+		t.Error("router middlewares should be applied to static files served from root path")
+	}
+	if w.Header().Get("X-Intercepted") != "yes" {
+		t.Error("router interceptors should be applied to static files served from root path")
+	}
+
+	for _, tt := range tests {
+		w := serveStatic(router, tt.method, tt.path, nil)
+		if w.Code != tt.code {
+			t.Errorf("%s %s: wrong status code: want %d, got %d", tt.method, tt.path, tt.code, w.Code)
+			continue
+		}
+		if got := w.Body.String(); got != tt.body {
+			t.Errorf("%s %s: wrong body: want %q, got %q", tt.method, tt.path, tt.body, got)
+		}
+	}
+}
+
+func TestRouterStaticAttach(t *testing.T) {
+	assets := NewRouter()
+	assets.Static("/assets", staticTestFS())
+	spa := NewRouter()
+	spa.Static("/", staticTestFS())
+
+	router := NewRouter()
+	router.Attach("/app", assets)
+	router.Attach("/spa", spa)
+
+	for _, path := range []string{"/app/assets/app.js", "/spa/app.js"} {
+		w := serveStatic(router, http.MethodGet, path, nil)
+		if w.Code != http.StatusOK || w.Body.String() != "console.log('app')" {
+			t.Errorf("%s: files should be served relative to the mount point, got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+}
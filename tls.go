@@ -0,0 +1,212 @@
+package flow
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// TLSOptions holds TLS configuration Options.
+// Application is served over TLS when CertFile and KeyFile are set.
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds PEM encoded certificates of authorities which sign client certificates.
+	// Client certificates are required and verified when the file is set, unless ClientAuth is set.
+	// Verified client certificate is returned by ClientCertificate.
+	ClientCAFile string
+	ClientAuth   tls.ClientAuthType
+	// TLSReloadInterval is the interval of checking certificate files for changes,
+	// zero disables checking
+	TLSReloadInterval time.Duration
+	// TLSReloadSignals reload certificate files, SIGHUP by default
+	TLSReloadSignals []os.Signal
+	// ConfigureTLS customises TLS configuration created from the options
+	ConfigureTLS func(cfg *tls.Config) error
+}
+
+// enabled returns true when application is served over TLS
+func (o TLSOptions) enabled() bool {
+	return o.CertFile != "" || o.KeyFile != ""
+}
+
+// ClientCertificate returns verified client certificate of the request served over TLS,
+// it identifies the client when client certificates are verified, see TLSOptions.ClientCAFile
+func ClientCertificate(r *http.Request) (*x509.Certificate, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	return r.TLS.VerifiedChains[0][0], true
+}
+
+// newTLSConfig creates TLS configuration from options, certificate is loaded by returned reloader
+func newTLSConfig(opts TLSOptions) (*tls.Config, *certReloader, error) {
+	reloader, err := newCertReloader(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		ClientAuth:     opts.ClientAuth,
+	}
+
+	if opts.ClientCAFile != "" {
+		data, err := ioutil.ReadFile(opts.ClientCAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read client CA file `%s`. Error: %w", opts.ClientCAFile, err)
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(data) {
+			return nil, nil, fmt.Errorf("unable to parse client CA file `%s`. Error: %w", opts.ClientCAFile, errors.New("no PEM encoded certificates found"))
+		}
+		if cfg.ClientAuth == tls.NoClientCert {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	if opts.ConfigureTLS != nil {
+		if err := opts.ConfigureTLS(cfg); err != nil {
+			return nil, nil, fmt.Errorf("unable to configure TLS. Error: %w", err)
+		}
+	}
+	return cfg, reloader, nil
+}
+
+// certReloader holds certificate loaded from files, which is reloaded
+// when files change or reload signal is received
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the last loaded certificate, see tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// reload loads certificate from files, previous certificate is kept when files are invalid.
+// Modification time of invalid files is recorded as well, so they are reloaded when they change again.
+func (r *certReloader) reload() error {
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.modTime = modTime
+	if err != nil {
+		return fmt.Errorf("unable to load TLS certificate `%s`. Error: %w", r.certFile, err)
+	}
+	r.cert = &cert
+	return nil
+}
+
+// changed returns true when certificate files were modified after the last reload
+func (r *certReloader) changed() bool {
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return !modTime.Equal(r.modTime)
+}
+
+// filesModTime returns the latest modification time of certificate files
+func (r *certReloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unable to load TLS certificate `%s`. Error: %w", file, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// watch reloads certificate when files change or reload signal is received,
+// until returned stop function is invoked. Reload errors are logged to errorLog.
+func (r *certReloader) watch(interval time.Duration, signals []os.Signal, errorLog *log.Logger) (stop func()) {
+	c := make(chan os.Signal, 1)
+	if len(signals) > 0 {
+		signal.Notify(c, signals...)
+	}
+
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		tick = ticker.C
+	}
+
+	logf := log.Printf
+	if errorLog != nil {
+		logf = errorLog.Printf
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+		for {
+			select {
+			case <-c:
+			case <-tick:
+				if !r.changed() {
+					continue
+				}
+			case <-done:
+				return
+			}
+			if err := r.reload(); err != nil {
+				logf("flow: %v", err)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,235 @@
+package flow
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testCA issues locally generated certificates used by TLS tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate CA key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "flow test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unable to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue creates PEM encoded certificate and key for given common name
+func (ca *testCA) issue(t *testing.T, cn string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unable to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeCert writes server certificate for given common name to cert and key files
+func (ca *testCA) writeCert(t *testing.T, certFile, keyFile, cn string) {
+	certPEM, keyPEM := ca.issue(t, cn, x509.ExtKeyUsageServerAuth)
+	if err := ioutil.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type identityRouter struct{}
+
+func (identityRouter) Path() string                         { return "/" }
+func (identityRouter) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (identityRouter) RegisterSubRouters() bool             { return false }
+func (identityRouter) ProvideHandlers() []Provider {
+	return []Provider{NewValueProvider(identityHandler{})}
+}
+
+type identityHandler struct{}
+
+func (identityHandler) Method() string                       { return http.MethodGet }
+func (identityHandler) Path() string                         { return "/identity" }
+func (identityHandler) Middlewares() []MiddlewareHandlerFunc { return nil }
+func (identityHandler) Handle(r *http.Request) Response {
+	cert, ok := ClientCertificate(r)
+	if !ok {
+		return ResponseText(http.StatusUnauthorized, "anonymous")
+	}
+	return ResponseText(http.StatusOK, cert.Subject.CommonName)
+}
+
+func TestModuleServeTLS(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+
+	opts := servedOptions(t)
+	opts.CertFile = filepath.Join(dir, "server.crt")
+	opts.KeyFile = filepath.Join(dir, "server.key")
+	opts.ClientCAFile = filepath.Join(dir, "ca.crt")
+	ca.writeCert(t, opts.CertFile, opts.KeyFile, "flow")
+	if err := ioutil.WriteFile(opts.ClientCAFile, ca.pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Bootstrap(&servedModule{
+		testModule: testModule{
+			routers: []Provider{NewValueProvider(identityRouter{})},
+		},
+		opts: opts,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	served := serveModule(t, m)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", opts.Addr[5:])
+			},
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs},
+		}}
+	}
+
+	certPEM, keyPEM := ca.issue(t, "client-1", x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := newClient(clientCert).Get("https://flow/identity")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "client-1" {
+		t.Errorf("wrong client identity: %d %q", resp.StatusCode, body)
+	}
+
+	// client certificate is required
+	if _, err := newClient().Get("https://flow/identity"); err == nil {
+		t.Error("expected error for client without certificate")
+	}
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	if err := <-served; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	ca.writeCert(t, certFile, keyFile, "first")
+
+	r, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	commonName := func() string {
+		cert, _ := r.GetCertificate(nil)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return leaf.Subject.CommonName
+	}
+	waitFor := func(cn string) {
+		for start := time.Now(); commonName() != cn; time.Sleep(5 * time.Millisecond) {
+			if time.Since(start) > time.Second {
+				t.Fatalf("certificate %q is not reloaded, got %q", cn, commonName())
+			}
+		}
+	}
+
+	if cn := commonName(); cn != "first" {
+		t.Fatalf("wrong certificate: %s", cn)
+	}
+
+	// invalid files keep previous certificate
+	if err := ioutil.WriteFile(certFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reload(); err == nil || commonName() != "first" {
+		t.Errorf("invalid certificate should not be loaded: %v", err)
+	}
+	if r.changed() {
+		t.Error("invalid files should not be reloaded until they change")
+	}
+
+	// changed files are reloaded
+	stop := r.watch(10*time.Millisecond, nil, nil)
+	ca.writeCert(t, certFile, keyFile, "second")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	waitFor("second")
+	stop()
+
+	// certificate is reloaded on signal
+	stop = r.watch(0, []os.Signal{syscall.SIGUSR2}, nil)
+	defer stop()
+	ca.writeCert(t, certFile, keyFile, "third")
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	waitFor("third")
+}
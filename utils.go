@@ -6,7 +6,10 @@ import (
 
 func iterate(path, method string, routes Routes, root *node) Routes {
 	path += root.path
-	routes = append(routes, *root.handle)
+	// intermediate nodes of the tree do not have routes
+	if root.handle != nil {
+		routes = append(routes, *root.handle)
+	}
 	for _, child := range root.children {
 		routes = iterate(path, method, routes, child)
 	}
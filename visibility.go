@@ -0,0 +1,136 @@
+package flow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-flow/flow/v2/di"
+)
+
+// StrictExports enables strict export visibility between modules.
+//
+// By default every module resolves instances provided by its parent modules and
+// by previously imported sibling modules. In strict mode a module resolves only
+// its own imports, exports of the modules it imports, instances created by
+// global providers (see NewGlobalProvider) and values registered to the root
+// container before bootstrap. Dependencies on instances provided by other
+// modules fail bootstrap with VisibilityError.
+func StrictExports() BootstrapOption {
+	return func(m *Module) {
+		m.strict = true
+	}
+}
+
+// VisibilityError is returned in strict mode when module depends on an instance
+// provided by other modules, which is neither exported to the module nor global
+type VisibilityError struct {
+	// Module is the module chain of the module depending on the instance
+	Module string
+	// Providers are names of modules providing the instance
+	Providers []string
+	// Err is the dependency error returned by the module container
+	Err *di.DependencyError
+}
+
+func (e *VisibilityError) Error() string {
+	return fmt.Sprintf("%s in module chain %s is not visible to the module. Instance is provided by module `%s`, which does not export it to the module nor provide it globally",
+		e.Err, e.Module, strings.Join(e.Providers, "`, `"))
+}
+
+// Unwrap returns the dependency error
+func (e *VisibilityError) Unwrap() error {
+	return e.Err
+}
+
+// childContainer returns container of the module imported by current module
+func (m *Module) childContainer() *di.Container {
+	if root := m.root(); root.strict {
+		return root.globals.Clone()
+	}
+	return m.container.Clone()
+}
+
+// registerGlobal adds instance created by global provider to containers of all modules
+// and to containers of modules imported afterwards, skipping already registered containers
+func (m *Module) registerGlobal(p Provider, obj interface{}, registered []*di.Container) {
+	root := m.root()
+	containers := make([]*di.Container, 0, len(registered)+1)
+	if root.globals != nil {
+		containers = append(containers, root.globals)
+	}
+	for _, module := range m.tree() {
+		containers = append(containers, module.container)
+	}
+
+next:
+	for _, c := range containers {
+		for _, r := range registered {
+			if c == r {
+				continue next
+			}
+		}
+		registered = append(registered, c)
+		register(c, p, obj)
+	}
+}
+
+// tree returns all modules created so far, including modules under construction
+func (m *Module) tree() []*Module {
+	var modules []*Module
+	seen := map[*Module]bool{}
+
+	var walk func(module *Module)
+	walk = func(module *Module) {
+		if seen[module] {
+			return
+		}
+		seen[module] = true
+		modules = append(modules, module)
+		for _, child := range module.modules {
+			walk(child)
+		}
+	}
+
+	// modules under construction are not yet imported by their parents
+	var ancestors []*Module
+	for a := m; a != nil; a = a.parent {
+		ancestors = append(ancestors, a)
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		walk(ancestors[i])
+	}
+	return modules
+}
+
+// visibilityError returns VisibilityError in strict mode when missing dependency
+// described by err is provided by other modules, otherwise it returns nil
+func (m *Module) visibilityError(err error) error {
+	var depErr *di.DependencyError
+	if !m.root().strict || !errors.As(err, &depErr) || depErr.Err != nil {
+		return nil
+	}
+
+	d := di.Dependency{Type: depErr.Type, Name: depErr.Name}
+	var providers []string
+	for _, module := range m.tree() {
+		if module == m {
+			continue
+		}
+		for _, n := range module.provided {
+			if n.provides(d) {
+				providers = append(providers, module.name)
+				break
+			}
+		}
+	}
+	if len(providers) == 0 {
+		return nil
+	}
+
+	return &VisibilityError{
+		Module:    m.chain(),
+		Providers: providers,
+		Err:       depErr,
+	}
+}
@@ -0,0 +1,128 @@
+package flow
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// siblingModule is configurable ModuleFactory imported next to childModule
+type siblingModule struct {
+	testModule
+}
+
+func TestStrictExports(t *testing.T) {
+	var repo *testRepo
+	var cache *testCache
+
+	m, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewGlobalProvider(func() *testConfig { return &testConfig{DSN: "db"} }),
+			NewProvider(func() *testCache { return &testCache{} }),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func(cfg *testConfig) *testDB { return &testDB{dsn: cfg.DSN} }),
+						NewGlobalProvider(func() *testService { return &testService{} }),
+					},
+					exports: []Provider{
+						NewProvider(func(db *testDB) *testRepo {
+							repo = &testRepo{db: db}
+							return repo
+						}),
+					},
+				}}
+			}),
+			NewProvider(func() *siblingModule {
+				return &siblingModule{testModule{
+					imports: []Provider{
+						NewProvider(func(cfg *testConfig, svc *testService) *testCache {
+							cache = &testCache{}
+							return cache
+						}),
+					},
+				}}
+			}),
+		},
+	}, StrictExports())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo == nil || repo.db.dsn != "db" || cache == nil {
+		t.Errorf("dependencies are not resolved: %+v, %+v", repo, cache)
+	}
+
+	// exports are visible to the importing module
+	if err := m.Invoke(func(r *testRepo) {}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	providers := m.Graph().Modules[0].Providers
+	if !providers[0].Global || providers[1].Global {
+		t.Errorf("wrong global providers in graph: %+v, %+v", providers[0], providers[1])
+	}
+}
+
+func TestStrictExportsParentImport(t *testing.T) {
+	_, err := Bootstrap(&testModule{
+		imports: []Provider{
+			NewProvider(func() *testDB { return &testDB{} }),
+		},
+		modules: []Provider{
+			NewProvider(func() *childModule {
+				return &childModule{testModule{
+					imports: []Provider{
+						NewProvider(func(db *testDB) *testRepo { return &testRepo{db: db} }),
+					},
+				}}
+			}),
+		},
+	}, StrictExports())
+
+	var verr *VisibilityError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected visibility error, got: %v", err)
+	}
+	if verr.Module != "flow.testModule -> flow.childModule" || !reflect.DeepEqual(verr.Providers, []string{"flow.testModule"}) {
+		t.Errorf("wrong visibility error: %v", verr)
+	}
+}
+
+func TestStrictExportsSiblingExport(t *testing.T) {
+	sibling := func() *siblingModule {
+		return &siblingModule{testModule{
+			exports: []Provider{
+				NewProvider(func() *testDB { return &testDB{} }),
+			},
+		}}
+	}
+	child := func() *childModule {
+		return &childModule{testModule{
+			imports: []Provider{
+				NewProvider(func(db *testDB) *testRepo { return &testRepo{db: db} }),
+			},
+		}}
+	}
+
+	// sibling exports are visible to modules imported afterwards
+	if _, err := Bootstrap(&testModule{
+		modules: []Provider{NewProvider(sibling), NewProvider(child)},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := Bootstrap(&testModule{
+		modules: []Provider{NewProvider(sibling), NewProvider(child)},
+	}, StrictExports())
+
+	var verr *VisibilityError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected visibility error, got: %v", err)
+	}
+	if !reflect.DeepEqual(verr.Providers, []string{"flow.siblingModule"}) || verr.Err.Type != reflect.TypeOf(&testDB{}) {
+		t.Errorf("wrong visibility error: %v", verr)
+	}
+}